@@ -1,23 +1,35 @@
 package api
 
 import (
+	"context"
+	"net/http"
 	"sync"
 
 	"github.com/eclipse-xfsc/statuslist-service/internal/config"
 	"github.com/eclipse-xfsc/statuslist-service/internal/database"
+	"github.com/eclipse-xfsc/statuslist-service/internal/signer"
 )
 
-var db *database.Database
-
-func Listen(database *database.Database, conf *config.StatusListConfiguration) {
+// Listen serves the REST and Nats interfaces and runs the scheduler until ctx is done. It returns once the scheduler finished its current run, the REST server and the Nats replier end with the process.
+func Listen(ctx context.Context, database *database.Database, sign signer.Signer, verifier signer.Verifier, conf *config.StatusListConfiguration) {
 	var wg sync.WaitGroup
 
-	db = database
+	env := &apienv{
+		db:          database,
+		signer:      sign,
+		conf:        conf,
+		signedLists: newSignedListCache(conf.SignedListCacheSize),
+		client:      &http.Client{Timeout: conf.FetchTimeout},
+		verifier:    verifier,
+	}
+
+	go startMessaging(env)
 
-	wg.Add(2)
-	go startMessaging(conf, &wg)
+	go startRest(env)
 
-	go startRest(conf, &wg, db)
+	wg.Add(1)
+	go startScheduler(ctx, conf, &wg, database, env.signedLists)
 
+	<-ctx.Done()
 	wg.Wait()
 }
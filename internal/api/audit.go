@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/gin-gonic/gin"
+	logger "github.com/sirupsen/logrus"
+)
+
+func (env *apienv) handleGetStatusChanges(ctx *gin.Context) {
+	tenantId := ctx.Param("tenantId")
+
+	filter, err := statusChangeFilter(ctx)
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	offset, limit, ok := pageParams(ctx)
+	if !ok {
+		return
+	}
+
+	changes, total, err := env.db.GetStatusChanges(ctx, tenantId, filter, offset, limit)
+	if err != nil {
+		logger.Error("Error reading status changes", err.Error())
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"tenantId": tenantId,
+		"offset":   offset,
+		"limit":    limit,
+		"total":    total,
+		"changes":  changes,
+	})
+}
+
+// statusChangeFilter reads the filter of the audit log from the query parameters listId, index, actor, from and to.
+func statusChangeFilter(ctx *gin.Context) (entity.StatusChangeFilter, error) {
+	filter := entity.StatusChangeFilter{Actor: ctx.Query("actor")}
+
+	if ctx.Query("listId") != "" {
+		listId, err := queryInt(ctx, "listId", 0)
+		if err != nil {
+			return filter, fmt.Errorf("%w: invalid listId %s", errInvalidRequest, ctx.Query("listId"))
+		}
+		filter.ListId = &listId
+	}
+
+	if ctx.Query("index") != "" {
+		index, err := queryInt(ctx, "index", 0)
+		if err != nil {
+			return filter, fmt.Errorf("%w: invalid index %s", errInvalidRequest, ctx.Query("index"))
+		}
+		filter.Index = &index
+	}
+
+	var err error
+	if filter.From, err = queryTime(ctx, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = queryTime(ctx, "to"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// queryTime parses the query parameter name as RFC 3339 timestamp, a missing parameter is the zero time.
+func queryTime(ctx *gin.Context, name string) (time.Time, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s is not an RFC 3339 timestamp: %s", errInvalidRequest, name, value)
+	}
+	return t, nil
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStatusChanges(t *testing.T) {
+	var filter entity.StatusChangeFilter
+	env := newTestEnv(&fakeConnection{changes: func(f entity.StatusChangeFilter) []entity.StatusChange {
+		filter = f
+		return []entity.StatusChange{{TenantId: "tenant", ListId: 1, Index: 4, OldStatus: 0, NewStatus: 1, Actor: "alice", Reason: entity.ReasonSuperseded}}
+	}})
+
+	res := serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/audit?listId=1&index=4&actor=alice&from=2024-01-01T00:00:00Z", nil)
+	require.Equal(t, http.StatusOK, res.Code)
+
+	require.Equal(t, 1, *filter.ListId)
+	require.Equal(t, 4, *filter.Index)
+	require.Equal(t, "alice", filter.Actor)
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), filter.From)
+	require.True(t, filter.To.IsZero())
+
+	var body struct {
+		Total   int                   `json:"total"`
+		Changes []entity.StatusChange `json:"changes"`
+	}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	require.Equal(t, 1, body.Total)
+	require.Equal(t, entity.ReasonSuperseded, body.Changes[0].Reason)
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/audit?to=yesterday", nil)
+	require.Equal(t, http.StatusBadRequest, res.Code)
+
+	for _, page := range []string{"offset=-1", "limit=0", "limit=501", "limit=ten"} {
+		res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/audit?"+page, nil)
+		require.Equal(t, http.StatusBadRequest, res.Code, page)
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/config"
+)
+
+// signedListKey identifies a signed artifact of a list. Format is the content type and for credentials the list type, Key everything which ends up in the signature like the key reference, did and host.
+type signedListKey struct {
+	TenantId string
+	ListId   int
+	Format   string
+	Key      string
+}
+
+type signedList struct {
+	Data []byte
+	// Digest of the unsigned list the artifact was built from
+	Digest    [sha256.Size]byte
+	SignedAt  time.Time
+	RefreshAt time.Time
+}
+
+// signedListCache keeps signed lists until the list changes or their validity is about to expire, so that verifier traffic does not reach the signer. It holds at most maxEntries artifacts and evicts the least recently used one first.
+type signedListCache struct {
+	mutex      sync.Mutex
+	entries    map[signedListKey]*list.Element
+	order      *list.List
+	maxEntries int
+	now        func() time.Time
+}
+
+type signedListElement struct {
+	key   signedListKey
+	entry signedList
+}
+
+func newSignedListCache(maxEntries int) *signedListCache {
+	return &signedListCache{
+		entries:    make(map[signedListKey]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// Get returns the artifact for key if it was built from the same list and does not need a refresh yet. Comparing the list also catches changes made by other instances of the service. Artifacts which are not served anymore are dropped.
+func (c *signedListCache) Get(key signedListKey, statusList []byte) (signedList, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return signedList{}, false
+	}
+
+	entry := element.Value.(*signedListElement).entry
+	if entry.Digest != sha256.Sum256(statusList) || !c.now().Before(entry.RefreshAt) {
+		c.remove(element)
+		return signedList{}, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry, true
+}
+
+// Put stores the artifact built from statusList. It is served until refreshAt.
+func (c *signedListCache) Put(key signedListKey, statusList []byte, data []byte, refreshAt time.Time) signedList {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry := signedList{
+		Data:      data,
+		Digest:    sha256.Sum256(statusList),
+		SignedAt:  c.now(),
+		RefreshAt: refreshAt,
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*signedListElement).entry = entry
+		c.order.MoveToFront(element)
+		return entry
+	}
+
+	c.entries[key] = c.order.PushFront(&signedListElement{key: key, entry: entry})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+
+	return entry
+}
+
+// Invalidate drops every artifact of the list.
+func (c *signedListCache) Invalidate(tenantId string, listId int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key, element := range c.entries {
+		if key.TenantId == tenantId && key.ListId == listId {
+			c.remove(element)
+		}
+	}
+}
+
+func (c *signedListCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*signedListElement).key)
+}
+
+// refreshAt returns when an artifact signed now has to be signed again. Verifiers may cache a list for the ttl, so an artifact is only served while it stays valid for longer than the ttl.
+func (c *signedListCache) refreshAt(conf *config.StatusListConfiguration, tenantId string) time.Time {
+	return c.now().Add(conf.ListValidityForTenant(tenantId) - conf.ListTtlForTenant(tenantId))
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedListCache(t *testing.T) {
+	cache := newSignedListCache(10)
+	key := signedListKey{TenantId: "tenant", ListId: 1, Format: ContentTypeStatusListJwt, Key: "key"}
+	list := []byte{0x01, 0x00}
+
+	_, ok := cache.Get(key, list)
+	require.False(t, ok)
+
+	cache.Put(key, list, []byte("token"), time.Now().Add(time.Hour))
+	entry, ok := cache.Get(key, list)
+	require.True(t, ok)
+	require.Equal(t, []byte("token"), entry.Data)
+
+	// changed by another instance
+	_, ok = cache.Get(key, []byte{0x03, 0x00})
+	require.False(t, ok)
+
+	cache.Invalidate("tenant", 1)
+	_, ok = cache.Get(key, list)
+	require.False(t, ok)
+}
+
+func TestSignedListCacheRefresh(t *testing.T) {
+	c := &config.StatusListConfiguration{
+		ListValidity: time.Hour,
+		ListTtl:      5 * time.Minute,
+	}
+
+	now := time.Now()
+	cache := newSignedListCache(10)
+	cache.now = func() time.Time { return now }
+
+	refreshAt := cache.refreshAt(c, "tenant")
+	require.Equal(t, now.Add(55*time.Minute), refreshAt)
+
+	key := signedListKey{TenantId: "tenant", ListId: 1, Format: ContentTypeStatusListCwt}
+	cache.Put(key, nil, []byte("token"), refreshAt)
+
+	now = now.Add(54 * time.Minute)
+	_, ok := cache.Get(key, nil)
+	require.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.Get(key, nil)
+	require.False(t, ok)
+}
+
+func TestSignedListCacheBound(t *testing.T) {
+	now := time.Now()
+	cache := newSignedListCache(2)
+	cache.now = func() time.Time { return now }
+
+	key := func(listId int) signedListKey {
+		return signedListKey{TenantId: "tenant", ListId: listId, Format: ContentTypeStatusListJwt}
+	}
+
+	cache.Put(key(1), nil, []byte("1"), now.Add(time.Hour))
+	cache.Put(key(2), nil, []byte("2"), now.Add(time.Minute))
+	_, ok := cache.Get(key(1), nil)
+	require.True(t, ok)
+
+	// the least recently used list is evicted
+	cache.Put(key(3), nil, []byte("3"), now.Add(time.Hour))
+	_, ok = cache.Get(key(2), nil)
+	require.False(t, ok)
+	_, ok = cache.Get(key(1), nil)
+	require.True(t, ok)
+
+	// artifacts which need a refresh are dropped
+	now = now.Add(2 * time.Hour)
+	_, ok = cache.Get(key(3), nil)
+	require.False(t, ok)
+	require.Len(t, cache.entries, 1)
+	require.Equal(t, 1, cache.order.Len())
+}
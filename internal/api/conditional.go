@@ -0,0 +1,83 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeCacheable answers with body or with 304 if the client already holds it.
+func writeCacheable(ctx *gin.Context, contentType string, etag string, body []byte, lastModified time.Time, ttl time.Duration) {
+	setCacheHeaders(ctx, etag, ttl)
+	ctx.Writer.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+
+	if notModified(ctx.Request.Header, etag, lastModified) {
+		ctx.Status(http.StatusNotModified)
+		ctx.Writer.WriteHeaderNow()
+		return
+	}
+
+	ctx.Data(http.StatusOK, contentType, body)
+}
+
+// writeNotModified answers with 304 if If-None-Match matches etag. It needs no body, so signed lists are only signed for clients which do not hold them yet.
+func writeNotModified(ctx *gin.Context, etag string, ttl time.Duration) bool {
+	if ctx.Request.Header.Get("If-None-Match") == "" || !notModified(ctx.Request.Header, etag, time.Time{}) {
+		return false
+	}
+
+	setCacheHeaders(ctx, etag, ttl)
+	ctx.Status(http.StatusNotModified)
+	ctx.Writer.WriteHeaderNow()
+	return true
+}
+
+func setCacheHeaders(ctx *gin.Context, etag string, ttl time.Duration) {
+	header := ctx.Writer.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "public, max-age="+strconv.FormatInt(int64(ttl.Seconds()), 10))
+	header.Add("Vary", listVary)
+}
+
+// listVary names the request headers which select the representation of a list, see negotiateListContentType and the signing headers of handleGetList.
+const listVary = "Accept, Content-Type, X-Key, X-Did, X-Namespace, X-Group, X-Host, X-Type"
+
+// listETag derives a strong ETag from the version of a list and the format of its representation.
+func listETag(tenantId string, listId int, version int64, format string) string {
+	digest := sha256.Sum256([]byte(strings.Join([]string{tenantId, strconv.Itoa(listId), strconv.FormatInt(version, 10), format}, "|")))
+	return `"` + hex.EncodeToString(digest[:16]) + `"`
+}
+
+// signedListETag derives a weak ETag for a signed representation. The format includes the signing parameters. Every signing yields other bytes, e.g. by iat and exp, so the representations of a version are only equivalent.
+func signedListETag(tenantId string, listId int, version int64, format string) string {
+	return "W/" + listETag(tenantId, listId, version, format)
+}
+
+// notModified evaluates If-None-Match and, only without it, If-Modified-Since as defined by RFC 9110 section 13.2.2.
+func notModified(header http.Header, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			// If-None-Match uses the weak comparison
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := header.Get("If-Modified-Since"); ifModifiedSince != "" {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
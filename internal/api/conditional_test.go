@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/eclipse-xfsc/statuslist-service/internal/signer"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func serveCacheable(header http.Header, etag string, body []byte, lastModified time.Time) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/status/1", nil)
+	ctx.Request.Header = header
+
+	writeCacheable(ctx, ContentTypeOctetStream, etag, body, lastModified, 5*time.Minute)
+	return recorder
+}
+
+func TestWriteCacheable(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	etag := listETag("tenant", 1, 7, ContentTypeOctetStream)
+
+	res := serveCacheable(http.Header{}, etag, []byte{0x01}, lastModified)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, []byte{0x01}, res.Body.Bytes())
+	require.Equal(t, "public, max-age=300", res.Header().Get("Cache-Control"))
+	require.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", res.Header().Get("Last-Modified"))
+	require.Equal(t, etag, res.Header().Get("ETag"))
+	require.Equal(t, listVary, res.Header().Get("Vary"))
+
+	res = serveCacheable(http.Header{"If-None-Match": {`"other", ` + etag}}, etag, []byte{0x01}, lastModified)
+	require.Equal(t, http.StatusNotModified, res.Code)
+	require.Empty(t, res.Body.Bytes())
+
+	res = serveCacheable(http.Header{"If-None-Match": {etag}}, listETag("tenant", 1, 8, ContentTypeOctetStream), []byte{0x03}, lastModified)
+	require.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestListETag(t *testing.T) {
+	etag := listETag("tenant", 1, 7, ContentTypeOctetStream)
+	require.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+	require.Equal(t, etag, listETag("tenant", 1, 7, ContentTypeOctetStream))
+
+	require.NotEqual(t, etag, listETag("other", 1, 7, ContentTypeOctetStream))
+	require.NotEqual(t, etag, listETag("tenant", 2, 7, ContentTypeOctetStream))
+	require.NotEqual(t, etag, listETag("tenant", 1, 8, ContentTypeOctetStream))
+	require.NotEqual(t, etag, listETag("tenant", 1, 7, ContentTypeJson))
+
+	weak := signedListETag("tenant", 1, 7, ContentTypeVcJwt)
+	require.Equal(t, "W/"+listETag("tenant", 1, 7, ContentTypeVcJwt), weak)
+	require.True(t, notModified(http.Header{"If-None-Match": {weak}}, weak, time.Time{}))
+}
+
+type countingSigner struct {
+	signer.Signer
+	jwts int
+}
+
+func (s *countingSigner) SignJwt(ctx context.Context, key signer.KeyRef, header map[string]interface{}, claims map[string]interface{}) ([]byte, error) {
+	s.jwts++
+	return []byte("header.claims.signature"), nil
+}
+
+func TestGetListNotModifiedSkipsSigning(t *testing.T) {
+	list := entity.NewListWithOptions(1, entity.ListOptions{Bits: 1, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	list.LastUpdate = time.Now()
+	sign := &countingSigner{}
+	env := newTestEnv(&fakeConnection{lists: map[int]*entity.List{1: list}})
+	env.signer = sign
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/v1/tenants/tenant/status/1", nil)
+		request.Header = header
+		request.Header.Set("Accept", ContentTypeVcJwt)
+		return serveRequest(env, request)
+	}
+
+	res := get(http.Header{})
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, 1, sign.jwts)
+	etag := res.Header().Get("ETag")
+	require.True(t, strings.HasPrefix(etag, "W/"))
+
+	// without the cached signature a matching client is still not signed for
+	env.signedLists = newSignedListCache(10)
+	res = get(http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusNotModified, res.Code)
+	require.Equal(t, etag, res.Header().Get("ETag"))
+	require.Equal(t, 1, sign.jwts)
+
+	// other signing parameters are another representation
+	res = get(http.Header{"If-None-Match": {etag}, "X-Key": {"other"}})
+	require.Equal(t, http.StatusOK, res.Code)
+	require.NotEqual(t, etag, res.Header().Get("ETag"))
+	require.Equal(t, 2, sign.jwts)
+}
+
+func TestNotModifiedSince(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 500, time.UTC)
+
+	require.True(t, notModified(http.Header{"If-Modified-Since": {"Tue, 02 Jan 2024 03:04:05 GMT"}}, `"a"`, lastModified))
+	require.False(t, notModified(http.Header{"If-Modified-Since": {"Tue, 02 Jan 2024 03:04:04 GMT"}}, `"a"`, lastModified))
+	require.False(t, notModified(http.Header{"If-Modified-Since": {"invalid"}}, `"a"`, lastModified))
+
+	// If-None-Match takes precedence
+	require.False(t, notModified(http.Header{"If-None-Match": {`"b"`}, "If-Modified-Since": {"Tue, 02 Jan 2024 03:04:05 GMT"}}, `"a"`, lastModified))
+}
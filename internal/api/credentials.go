@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// credentialRef reads the reference of a credential from the path or, for references with slashes, from the query parameter ref.
+func credentialRef(ctx *gin.Context) (string, error) {
+	ref := ctx.Param("ref")
+	if ref == "" {
+		ref = ctx.Query("ref")
+	}
+	if ref == "" {
+		return "", fmt.Errorf("%w: missing credential reference", errInvalidRequest)
+	}
+	return ref, nil
+}
+
+// credentialEntry resolves the reference of the request to its entry.
+func (env *apienv) credentialEntry(ctx *gin.Context, tenantId string) (*entity.CredentialEntry, error) {
+	ref, err := credentialRef(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return env.db.GetCredentialEntry(ctx, tenantId, ref)
+}
+
+func (env *apienv) handleGetCredential(ctx *gin.Context) {
+	tenantId := ctx.Param("tenantId")
+
+	credential, err := env.credentialEntry(ctx, tenantId)
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	list, at, err := env.statusList(ctx, tenantId, credential.ListId)
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	entry, err := env.entryStatus(ctx, tenantId, credential.ListId, credential.Index, list, at)
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+	entry["credentialRef"] = credential.Ref
+
+	ctx.JSON(http.StatusOK, entry)
+}
+
+func (env *apienv) handleCredentialRevoke(ctx *gin.Context) {
+	env.handleCredentialStatusChange(ctx, env.db.RevokeCredentialInSpecifiedList, "revoked")
+}
+
+func (env *apienv) handleCredentialSuspend(ctx *gin.Context) {
+	env.handleCredentialStatusChange(ctx, env.db.SuspendCredentialInSpecifiedList, "suspended")
+}
+
+func (env *apienv) handleCredentialUnsuspend(ctx *gin.Context) {
+	env.handleCredentialStatusChange(ctx, env.db.UnsuspendCredentialInSpecifiedList, "valid")
+}
+
+func (env *apienv) handleCredentialStatusChange(ctx *gin.Context, change statusChange, status string) {
+	tenantId := ctx.Param("tenantId")
+
+	credential, err := env.credentialEntry(ctx, tenantId)
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	response, err := env.changeEntryStatus(ctx, tenantId, entity.Entry{ListId: credential.ListId, Index: credential.Index}, change, status)
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+	response["credentialRef"] = credential.Ref
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialRevoke(t *testing.T) {
+	list := entity.NewListWithOptions(1, entity.ListOptions{Bits: 1, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	_, err := list.AllocateIndices(4)
+	require.NoError(t, err)
+	list.LastUpdate = time.Now()
+
+	ref := "urn:uuid:3978344f-8596-4c3a-a978-8fcaba3903c5"
+	fake := &fakeConnection{
+		lists:       map[int]*entity.List{1: list},
+		credentials: map[string]*entity.CredentialEntry{ref: {Ref: ref, ListId: 1, Index: 3}},
+	}
+	env := newTestEnv(fake)
+
+	res := serveRest(env, http.MethodPost, "/v1/tenants/tenant/credentials/"+ref+"/revoke", nil)
+	require.Equal(t, http.StatusOK, res.Code)
+
+	var body struct {
+		CredentialRef string `json:"credentialRef"`
+		ListId        int    `json:"listId"`
+		Index         int    `json:"index"`
+		Status        string `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	require.Equal(t, ref, body.CredentialRef)
+	require.Equal(t, 3, body.Index)
+	require.Equal(t, "revoked", body.Status)
+
+	revoked, err := list.IsRevoked(3)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/credentials/"+ref, nil)
+	require.Equal(t, http.StatusOK, res.Code)
+
+	var entry struct {
+		CredentialRef string `json:"credentialRef"`
+		Revoked       bool   `json:"revoked"`
+	}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &entry))
+	require.Equal(t, ref, entry.CredentialRef)
+	require.True(t, entry.Revoked)
+}
+
+func TestCredentialRefQuery(t *testing.T) {
+	list := entity.NewListWithOptions(1, entity.ListOptions{Bits: 1, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	_, err := list.AllocateIndices(1)
+	require.NoError(t, err)
+
+	ref := "https://issuer.example/credentials/42"
+	fake := &fakeConnection{
+		lists:       map[int]*entity.List{1: list},
+		credentials: map[string]*entity.CredentialEntry{ref: {Ref: ref, ListId: 1, Index: 0}},
+	}
+	env := newTestEnv(fake)
+
+	res := serveRest(env, http.MethodPost, "/v1/tenants/tenant/credentials/revoke?ref="+url.QueryEscape(ref), nil)
+	require.Equal(t, http.StatusOK, res.Code)
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/credentials?ref="+url.QueryEscape("https://issuer.example/credentials/43"), nil)
+	require.Equal(t, http.StatusNotFound, res.Code)
+	require.Contains(t, res.Body.String(), "credential-not-found")
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/credentials", nil)
+	require.Equal(t, http.StatusBadRequest, res.Code)
+}
@@ -0,0 +1,17 @@
+package api
+
+// Claim keys of the CWT form, see https://datatracker.ietf.org/doc/draft-ietf-oauth-status-list/ section 6.2
+const (
+	cwtClaimIss        = 1
+	cwtClaimSub        = 2
+	cwtClaimExp        = 4
+	cwtClaimIat        = 6
+	cwtClaimStatusList = 65533
+	cwtClaimTtl        = 65534
+)
+
+// Header labels of COSE, see RFC 9052 section 3.1
+const (
+	coseHeaderKid = 4
+	coseHeaderTyp = 16
+)
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/config"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+)
+
+// ListTypeTokenStatusList references entries from SD-JWT and mdoc credentials by the status claim of the Token Status List.
+const ListTypeTokenStatusList = "TokenStatusList"
+
+var errInvalidCount = errors.New("invalid number of entries")
+var errInvalidSchedule = errors.New("scheduled time must be in the future")
+
+// statusListEntryRequest holds the inputs of a creation request which are shared by Nats and REST.
+type statusListEntryRequest struct {
+	Origin         string `json:"origin"`
+	Type           string `json:"type,omitempty"`
+	Bits           int    `json:"bits,omitempty"`
+	AllocationMode string `json:"allocationMode,omitempty"`
+	Purpose        string `json:"purpose,omitempty"`
+	// RevokeAt schedules the revocation of the entry, on suspension lists its suspension
+	RevokeAt *time.Time `json:"revokeAt,omitempty"`
+	// ExpiresAt schedules the revocation of the entry because the credential expires
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// CredentialRef registers the entry under a reference of the credential, e.g. its id or jti
+	CredentialRef string `json:"credentialRef,omitempty"`
+	// IdempotencyKey identifies repetitions of the request, it is taken from the Nats request id or the Idempotency-Key header
+	IdempotencyKey string `json:"-"`
+}
+
+// statusListEntry is an allocated entry with everything an issuer needs to reference it.
+type statusListEntry struct {
+	ListId    int    `json:"listId"`
+	Index     int    `json:"index"`
+	StatusUrl string `json:"statusUrl"`
+	Type      string `json:"type"`
+	Purpose   string `json:"purpose"`
+	Bits      int    `json:"bits"`
+
+	RevokeAt      *time.Time `json:"revokeAt,omitempty"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+	CredentialRef string     `json:"credentialRef,omitempty"`
+
+	CredentialStatus map[string]interface{} `json:"credentialStatus"`
+}
+
+// createStatusListEntry allocates an entry for the tenant in a list matching the request and the configured defaults.
+func (env *apienv) createStatusListEntry(ctx context.Context, tenantId string, request statusListEntryRequest) (*statusListEntry, error) {
+	entries, err := env.createStatusListEntries(ctx, tenantId, request, 1, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries[0], nil
+}
+
+// createStatusListEntries allocates count entries in one transaction. Sequential lists return the lowest free indices. The credential references are registered for the entries in order, without count one entry per reference is allocated. A repetition of a request with idempotency key returns the entries of the first request.
+func (env *apienv) createStatusListEntries(ctx context.Context, tenantId string, request statusListEntryRequest, count int, credentialRefs []string) ([]*statusListEntry, error) {
+	c := env.conf
+	if len(credentialRefs) == 0 && request.CredentialRef != "" {
+		credentialRefs = []string{request.CredentialRef}
+	}
+
+	if count == 0 {
+		count = len(credentialRefs)
+	}
+
+	if count < 1 || count > c.MaxBatchSize {
+		return nil, fmt.Errorf("%w: %d not between 1 and %d", errInvalidCount, count, c.MaxBatchSize)
+	}
+
+	template, err := newEntryTemplate(c, tenantId, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(credentialRefs) > 0 && len(credentialRefs) != count {
+		return nil, fmt.Errorf("%w: %d credential references for %d entries", errInvalidCount, len(credentialRefs), count)
+	}
+
+	for _, ref := range credentialRefs {
+		if err := entity.ValidateCredentialRef(ref); err != nil {
+			return nil, err
+		}
+	}
+
+	actions, err := scheduledActions(request, template.options, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var idempotency *entity.IdempotencyKey
+	if request.IdempotencyKey != "" && c.IdempotencyWindow > 0 {
+		idempotency = &entity.IdempotencyKey{
+			Key:         request.IdempotencyKey,
+			Fingerprint: requestFingerprint(request, count, credentialRefs),
+			Window:      c.IdempotencyWindow,
+		}
+		if err := idempotency.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := env.db.CreateTableForTenantIdIfNotExists(ctx, tenantId); err != nil {
+		return nil, err
+	}
+
+	statusData, err := env.db.AllocateIndicesInCurrentList(ctx, tenantId, template.options, count, entity.Allocation{Actions: actions, CredentialRefs: credentialRefs, Idempotency: idempotency})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*statusListEntry, len(statusData))
+	for i, data := range statusData {
+		if i == 0 || data.ListId != statusData[i-1].ListId {
+			env.signedLists.Invalidate(tenantId, data.ListId)
+		}
+
+		entries[i] = template.entry(data)
+		entries[i].RevokeAt = request.RevokeAt
+		entries[i].ExpiresAt = request.ExpiresAt
+		if len(credentialRefs) > 0 {
+			entries[i].CredentialRef = credentialRefs[i]
+		}
+	}
+
+	return entries, nil
+}
+
+// requestFingerprint hashes the content of a creation request. Repetitions under the same idempotency key must have the same content.
+func requestFingerprint(request statusListEntryRequest, count int, credentialRefs []string) string {
+	content, _ := json.Marshal(struct {
+		Request        statusListEntryRequest `json:"request"`
+		Count          int                    `json:"count"`
+		CredentialRefs []string               `json:"credentialRefs"`
+	}{request, count, credentialRefs})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// entryTemplate holds the validated options of a creation request completed with the configured defaults.
+type entryTemplate struct {
+	options  entity.ListOptions
+	listType string
+	origin   string
+}
+
+func newEntryTemplate(c *config.StatusListConfiguration, tenantId string, request statusListEntryRequest) (entryTemplate, error) {
+	template := entryTemplate{
+		options:  listOptions(c, tenantId, request.Bits, request.AllocationMode, request.Purpose),
+		listType: request.Type,
+		origin:   request.Origin,
+	}
+
+	if err := template.options.Validate(); err != nil {
+		return template, err
+	}
+
+	if template.listType == "" {
+		template.listType = c.DefaultListType
+	}
+
+	if !validListType(template.listType) {
+		return template, fmt.Errorf("%w: %s", errUnknownListType, template.listType)
+	}
+
+	if template.origin == "" {
+		template.origin = c.DefaultHost
+	}
+
+	return template, nil
+}
+
+// entry describes the allocated entry with the options of the template.
+func (t entryTemplate) entry(data *entity.StatusData) *statusListEntry {
+	entry := &statusListEntry{
+		ListId:    data.ListId,
+		Index:     data.Index,
+		StatusUrl: t.origin + data.StatusUrl,
+		Type:      t.listType,
+		Purpose:   t.options.Purpose,
+		Bits:      t.options.Bits,
+	}
+	entry.CredentialStatus = entry.credentialStatus()
+
+	return entry
+}
+
+// scheduledActions plans the revocation of the requested entries. Lists which only support suspension suspend the entries instead.
+func scheduledActions(request statusListEntryRequest, options entity.ListOptions, now time.Time) ([]entity.ScheduledAction, error) {
+	action := entity.ActionRevoke
+	if options.Purpose == entity.PurposeSuspension && options.Bits == 1 {
+		action = entity.ActionSuspend
+	}
+
+	var actions []entity.ScheduledAction
+
+	for _, scheduled := range []struct {
+		at     *time.Time
+		reason string
+	}{
+		{request.RevokeAt, entity.ReasonUnspecified},
+		{request.ExpiresAt, entity.ReasonCessationOfOperation},
+	} {
+		if scheduled.at == nil {
+			continue
+		}
+		if !scheduled.at.After(now) {
+			return nil, fmt.Errorf("%w: %s", errInvalidSchedule, scheduled.at.Format(time.RFC3339))
+		}
+		actions = append(actions, entity.ScheduledAction{Action: action, Reason: scheduled.reason, DueAt: *scheduled.at})
+	}
+
+	return actions, nil
+}
+
+func validListType(listType string) bool {
+	return listType == ListTypeStatusList2021 || listType == ListTypeBitstring || listType == ListTypeTokenStatusList
+}
+
+// listOptions completes the options of a creation request with the configured defaults of the tenant.
+func listOptions(c *config.StatusListConfiguration, tenantId string, bits int, allocationMode string, purpose string) entity.ListOptions {
+	options := entity.ListOptions{
+		Bits:           bits,
+		AllocationMode: allocationMode,
+		Purpose:        purpose,
+	}
+
+	if options.Bits == 0 {
+		options.Bits = c.ListBits
+	}
+
+	if options.AllocationMode == "" {
+		options.AllocationMode = c.AllocationModeForTenant(tenantId)
+	}
+
+	if options.Purpose == "" {
+		options.Purpose = c.ListPurpose
+	}
+
+	return options
+}
+
+// credentialStatus returns the status reference which the issuer embeds into the credential.
+func (e *statusListEntry) credentialStatus() map[string]interface{} {
+	if e.Type == ListTypeTokenStatusList {
+		return map[string]interface{}{
+			"status_list": map[string]interface{}{
+				"idx": e.Index,
+				"uri": e.StatusUrl,
+			},
+		}
+	}
+
+	status := map[string]interface{}{
+		"id":                   e.StatusUrl + "#" + strconv.Itoa(e.Index),
+		"type":                 e.Type + "Entry",
+		"statusPurpose":        e.Purpose,
+		"statusListIndex":      strconv.Itoa(e.Index),
+		"statusListCredential": e.StatusUrl,
+	}
+
+	if e.Type == ListTypeBitstring && e.Bits > 1 {
+		status["statusPurpose"] = bitstringPurpose(e.Purpose, e.Bits)
+		status["statusSize"] = e.Bits
+	}
+
+	return status
+}
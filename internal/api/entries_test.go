@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	messaging "github.com/eclipse-xfsc/nats-message-library"
+	"github.com/eclipse-xfsc/nats-message-library/common"
+	"github.com/eclipse-xfsc/statuslist-service/internal/config"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListOptionsDefaults(t *testing.T) {
+	c := &config.StatusListConfiguration{
+		ListBits:         1,
+		ListPurpose:      entity.PurposeRevocation,
+		AllocationMode:   entity.AllocationSequential,
+		TenantAllocation: map[string]string{"tenant": entity.AllocationRandom},
+	}
+
+	options := listOptions(c, "tenant", 0, "", "")
+	require.Equal(t, entity.ListOptions{Bits: 1, AllocationMode: entity.AllocationRandom, Purpose: entity.PurposeRevocation}, options)
+
+	options = listOptions(c, "other", 2, "", entity.PurposeSuspension)
+	require.Equal(t, entity.ListOptions{Bits: 2, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeSuspension}, options)
+}
+
+func TestCredentialStatus(t *testing.T) {
+	entry := statusListEntry{
+		ListId:    3,
+		Index:     42,
+		StatusUrl: "https://example.com/status/3",
+		Type:      ListTypeStatusList2021,
+		Purpose:   entity.PurposeRevocation,
+		Bits:      1,
+	}
+
+	require.Equal(t, map[string]interface{}{
+		"id":                   "https://example.com/status/3#42",
+		"type":                 "StatusList2021Entry",
+		"statusPurpose":        "revocation",
+		"statusListIndex":      "42",
+		"statusListCredential": "https://example.com/status/3",
+	}, entry.credentialStatus())
+
+	entry.Type = ListTypeBitstring
+	entry.Bits = 2
+	status := entry.credentialStatus()
+	require.Equal(t, "BitstringStatusListEntry", status["type"])
+	require.Equal(t, 2, status["statusSize"])
+	require.Equal(t, entity.PurposeMessage, status["statusPurpose"])
+
+	entry.Type = ListTypeTokenStatusList
+	require.Equal(t, map[string]interface{}{
+		"status_list": map[string]interface{}{"idx": 42, "uri": "https://example.com/status/3"},
+	}, entry.credentialStatus())
+}
+
+func TestScheduledActions(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	revokeAt := now.Add(time.Hour)
+	expiresAt := now.Add(24 * time.Hour)
+
+	actions, err := scheduledActions(statusListEntryRequest{RevokeAt: &revokeAt, ExpiresAt: &expiresAt}, entity.ListOptions{Bits: 1, Purpose: entity.PurposeRevocation}, now)
+	require.NoError(t, err)
+	require.Equal(t, []entity.ScheduledAction{
+		{Action: entity.ActionRevoke, Reason: entity.ReasonUnspecified, DueAt: revokeAt},
+		{Action: entity.ActionRevoke, Reason: entity.ReasonCessationOfOperation, DueAt: expiresAt},
+	}, actions)
+
+	actions, err = scheduledActions(statusListEntryRequest{RevokeAt: &revokeAt}, entity.ListOptions{Bits: 1, Purpose: entity.PurposeSuspension}, now)
+	require.NoError(t, err)
+	require.Equal(t, entity.ActionSuspend, actions[0].Action)
+
+	actions, err = scheduledActions(statusListEntryRequest{}, entity.ListOptions{Bits: 1, Purpose: entity.PurposeRevocation}, now)
+	require.NoError(t, err)
+	require.Empty(t, actions)
+
+	_, err = scheduledActions(statusListEntryRequest{RevokeAt: &now}, entity.ListOptions{Bits: 1, Purpose: entity.PurposeRevocation}, now)
+	require.ErrorIs(t, err, errInvalidSchedule)
+}
+
+func TestIdempotentCreateEvent(t *testing.T) {
+	list := entity.NewListWithOptions(2, entity.ListOptions{Bits: 1, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	env := newTestEnv(&fakeConnection{lists: map[int]*entity.List{1: list}, requests: map[string]fakeAllocation{}})
+
+	create := func(request CreateStatusListEntryRequest) CreateStatusListEntryReply {
+		data, err := json.Marshal(request)
+		require.NoError(t, err)
+		return env.handleCreateEvent(context.Background(), data)
+	}
+
+	request := CreateStatusListEntryRequest{
+		CreateStatusListEntryRequest: messaging.CreateStatusListEntryRequest{
+			Request: common.Request{TenantId: "tenant", RequestId: "42"},
+		},
+	}
+
+	first := create(request)
+	require.Nil(t, first.Error)
+	redelivered := create(request)
+	require.Nil(t, redelivered.Error)
+	require.Equal(t, first.Index, redelivered.Index)
+	require.Equal(t, 15, list.Free)
+
+	request.Type = ListTypeTokenStatusList
+	reused := create(request)
+	require.NotNil(t, reused.Error)
+	require.Equal(t, http.StatusUnprocessableEntity, reused.Error.Status)
+	require.Contains(t, reused.Error.Msg, "idempotency-key-reused")
+
+	request.RequestId = "43"
+	other := create(request)
+	require.Nil(t, other.Error)
+	require.NotEqual(t, first.Index, other.Index)
+}
+
+func TestIdempotencyKeyHeader(t *testing.T) {
+	list := entity.NewListWithOptions(2, entity.ListOptions{Bits: 1, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	env := newTestEnv(&fakeConnection{lists: map[int]*entity.List{1: list}, requests: map[string]fakeAllocation{}})
+
+	create := func(key string) []statusListEntry {
+		request := httptest.NewRequest(http.MethodPost, "/v1/tenants/tenant/status/batch", strings.NewReader(`{"count": 3}`))
+		if key != "" {
+			request.Header.Set(HeaderIdempotencyKey, key)
+		}
+		recorder := serveRequest(env, request)
+		require.Equal(t, http.StatusCreated, recorder.Code)
+
+		var body struct {
+			Entries []statusListEntry `json:"entries"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		return body.Entries
+	}
+
+	first := create("retry-1")
+	require.Equal(t, first, create("retry-1"))
+	require.Equal(t, 13, list.Free)
+
+	// without key every request allocates
+	create("")
+	create("")
+	require.Equal(t, 7, list.Free)
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/eclipse-xfsc/nats-message-library/common"
+	"github.com/eclipse-xfsc/statuslist-service/internal/database"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/eclipse-xfsc/statuslist-service/internal/signer"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	logger "github.com/sirupsen/logrus"
+)
+
+const ContentTypeProblemJson = "application/problem+json"
+
+// HeaderRequestId carries the correlation id of a request. A given id is reused, otherwise the service creates one.
+const HeaderRequestId = "X-Request-Id"
+
+// problemTypeBase prefixes the codes of the catalog to the type URIs of problem details.
+const problemTypeBase = "urn:xfsc:statuslist:problem:"
+
+var errInvalidRequest = errors.New("invalid request")
+var errNotAcceptable = errors.New("none of the accepted content types is offered")
+
+// problemType is an entry of the error catalog which REST responses and Nats error replies share.
+type problemType struct {
+	Code   string
+	Title  string
+	Status int
+}
+
+// Type returns the URI which identifies the problem type.
+func (p problemType) Type() string {
+	return problemTypeBase + p.Code
+}
+
+var (
+	problemInvalidRequest      = problemType{"invalid-request", "The request is malformed", http.StatusBadRequest}
+	problemListNotFound        = problemType{"list-not-found", "The status list does not exist", http.StatusNotFound}
+	problemIndexOutOfRange     = problemType{"index-out-of-range", "The index is outside the status list", http.StatusNotFound}
+	problemCredentialNotFound  = problemType{"credential-not-found", "The credential reference is not registered", http.StatusNotFound}
+	problemReservationNotFound = problemType{"reservation-not-found", "The reservation does not exist or has ended", http.StatusNotFound}
+	problemNotAcceptable       = problemType{"not-acceptable", "The requested format is not offered", http.StatusNotAcceptable}
+	problemNotAllocated        = problemType{"not-allocated", "The index was never allocated", http.StatusConflict}
+	problemStatusConflict      = problemType{"status-conflict", "The status of the entry can not be changed", http.StatusConflict}
+	problemNotApplied          = problemType{"not-applied", "The entry was not changed because another entry failed", http.StatusConflict}
+	problemCredentialExists    = problemType{"credential-exists", "The credential reference is already registered", http.StatusConflict}
+	problemReservationExpired  = problemType{"reservation-expired", "The reservation is expired", http.StatusGone}
+	problemIdempotencyReused   = problemType{"idempotency-key-reused", "The idempotency key was used for another request", http.StatusUnprocessableEntity}
+	problemValidation          = problemType{"validation-failed", "The request contains invalid values", http.StatusUnprocessableEntity}
+	problemStatusListInvalid   = problemType{"status-list-invalid", "The status list credential could not be verified", http.StatusUnprocessableEntity}
+	problemDatabase            = problemType{"database-error", "The database failed to process the request", http.StatusInternalServerError}
+	problemDatabaseUnavailable = problemType{"database-unavailable", "The database is not reachable", http.StatusServiceUnavailable}
+	problemSigningKeyNotFound  = problemType{"signing-key-not-found", "The signing key is not configured", http.StatusInternalServerError}
+	problemSignerRejected      = problemType{"signer-rejected", "The signer rejected the request", http.StatusBadGateway}
+	problemStatusListFailed    = problemType{"status-list-unavailable", "The status list could not be retrieved", http.StatusBadGateway}
+	problemSignerUnavailable   = problemType{"signer-unavailable", "The signer is not reachable", http.StatusServiceUnavailable}
+	problemCapacityExhausted   = problemType{"capacity-exhausted", "The tenant has no free entries left", http.StatusInsufficientStorage}
+	problemInternal            = problemType{"internal-error", "The request failed", http.StatusInternalServerError}
+)
+
+// problemFor looks up the catalog entry of an error of the entity, database or signer layer.
+func problemFor(err error) problemType {
+	var pgErr *pgconn.PgError
+	var connectErr *pgconn.ConnectError
+
+	switch {
+	case errors.Is(err, errInvalidRequest):
+		return problemInvalidRequest
+	case errors.Is(err, entity.ErrListNotFound):
+		return problemListNotFound
+	case errors.Is(err, entity.ErrIndexOutOfRange):
+		return problemIndexOutOfRange
+	case errors.Is(err, entity.ErrCredentialNotFound):
+		return problemCredentialNotFound
+	case errors.Is(err, entity.ErrReservationNotFound):
+		return problemReservationNotFound
+	case errors.Is(err, errNotAcceptable), errors.Is(err, signer.ErrUnsupported):
+		return problemNotAcceptable
+	case errors.Is(err, entity.ErrNotAllocated):
+		return problemNotAllocated
+	case errors.Is(err, entity.ErrRevocationNotSupported),
+		errors.Is(err, entity.ErrSuspensionNotSupported),
+		errors.Is(err, entity.ErrAlreadyRevoked),
+		errors.Is(err, entity.ErrNotReleasable):
+		return problemStatusConflict
+	case errors.Is(err, database.ErrNotApplied):
+		return problemNotApplied
+	case errors.Is(err, entity.ErrCredentialRefExists):
+		return problemCredentialExists
+	case errors.Is(err, entity.ErrReservationExpired):
+		return problemReservationExpired
+	case errors.Is(err, entity.ErrIdempotencyKeyReused):
+		return problemIdempotencyReused
+	case errors.Is(err, entity.ErrInvalidStatus),
+		errors.Is(err, entity.ErrInvalidBits),
+		errors.Is(err, entity.ErrInvalidAllocationMode),
+		errors.Is(err, entity.ErrInvalidPurpose),
+		errors.Is(err, entity.ErrInvalidReason),
+		errors.Is(err, entity.ErrInvalidCredentialRef),
+		errors.Is(err, entity.ErrInvalidIdempotencyKey),
+		errors.Is(err, errUnknownListType),
+		errors.Is(err, errInvalidCount),
+		errors.Is(err, errInvalidSchedule),
+		errors.Is(err, errInvalidTtl):
+		return problemValidation
+	case errors.Is(err, errInvalidStatusList):
+		return problemStatusListInvalid
+	case errors.Is(err, errStatusListUnavailable):
+		return problemStatusListFailed
+	case errors.Is(err, entity.ErrFullyAllocated):
+		return problemCapacityExhausted
+	case errors.Is(err, signer.ErrKeyNotFound), errors.Is(err, signer.ErrUnsupportedKey):
+		return problemSigningKeyNotFound
+	case errors.Is(err, signer.ErrRejected):
+		return problemSignerRejected
+	case errors.Is(err, signer.ErrUnavailable):
+		return problemSignerUnavailable
+	case errors.As(err, &connectErr):
+		return problemDatabaseUnavailable
+	case errors.As(err, &pgErr):
+		return problemDatabase
+	}
+	return problemInternal
+}
+
+// errorStatus maps the errors of the entity, database and signer layer to the http status which REST responses and Nats error replies carry.
+func errorStatus(err error) int {
+	return problemFor(err).Status
+}
+
+// isClientError reports whether err was caused by the request and not by the service.
+func isClientError(err error) bool {
+	return errorStatus(err) < http.StatusInternalServerError
+}
+
+// problemDetails is the RFC 7807 body of failed REST requests.
+type problemDetails struct {
+	Type          string `json:"type"`
+	Title         string `json:"title"`
+	Status        int    `json:"status"`
+	Detail        string `json:"detail"`
+	Instance      string `json:"instance,omitempty"`
+	CorrelationId string `json:"correlationId"`
+}
+
+// newProblemDetails describes err. Errors of the service itself only carry the title, their cause is logged under the correlation id.
+func newProblemDetails(err error, correlationId string) problemDetails {
+	problem := problemFor(err)
+
+	detail := problem.Title
+	if problem.Status < http.StatusInternalServerError {
+		detail = err.Error()
+	}
+
+	return problemDetails{
+		Type:          problem.Type(),
+		Title:         problem.Title,
+		Status:        problem.Status,
+		Detail:        detail,
+		CorrelationId: correlationId,
+	}
+}
+
+// correlationId returns the id of the request. It is taken from the request or created once and returned in the response.
+func correlationId(ctx *gin.Context) string {
+	if id := ctx.GetString(HeaderRequestId); id != "" {
+		return id
+	}
+
+	id := ctx.GetHeader(HeaderRequestId)
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	ctx.Set(HeaderRequestId, id)
+	ctx.Header(HeaderRequestId, id)
+	return id
+}
+
+// abortWithProblem answers the request with the problem details of err.
+func abortWithProblem(ctx *gin.Context, err error) {
+	id := correlationId(ctx)
+
+	problem := newProblemDetails(err, id)
+	problem.Instance = ctx.Request.URL.Path
+
+	if problem.Status >= http.StatusInternalServerError {
+		logger.WithField("correlationId", id).Error(err)
+	}
+
+	ctx.Error(err)
+	ctx.Header("Content-Type", ContentTypeProblemJson)
+	ctx.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// replyError describes err for the requester of a Nats request with the codes of the catalog.
+func replyError(err error) *common.Error {
+	problem := newProblemDetails(err, uuid.NewString())
+
+	return &common.Error{
+		Id:     problem.CorrelationId,
+		Status: problem.Status,
+		Msg:    fmt.Sprintf("%s: %s", problemFor(err).Code, problem.Detail),
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/eclipse-xfsc/statuslist-service/internal/signer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorStatus(t *testing.T) {
+	require.Equal(t, http.StatusNotFound, errorStatus(fmt.Errorf("list 7: %w", entity.ErrListNotFound)))
+	require.Equal(t, http.StatusNotFound, errorStatus(fmt.Errorf("%w: 999999999", entity.ErrIndexOutOfRange)))
+	require.Equal(t, http.StatusConflict, errorStatus(fmt.Errorf("%w: 3", entity.ErrNotAllocated)))
+	require.Equal(t, http.StatusConflict, errorStatus(entity.ErrAlreadyRevoked))
+	require.Equal(t, http.StatusUnprocessableEntity, errorStatus(entity.ErrInvalidBits))
+	require.Equal(t, http.StatusInternalServerError, errorStatus(fmt.Errorf("connection refused")))
+
+	reply := replyError(entity.ErrNotAllocated)
+	require.Equal(t, http.StatusConflict, reply.Status)
+	require.Equal(t, "not-allocated: "+entity.ErrNotAllocated.Error(), reply.Msg)
+	require.NotEmpty(t, reply.Id)
+
+	reply = replyError(fmt.Errorf("signing: %w", signer.ErrUnavailable))
+	require.Equal(t, http.StatusServiceUnavailable, reply.Status)
+	require.Equal(t, "signer-unavailable: The signer is not reachable", reply.Msg)
+}
+
+func TestProblemDetails(t *testing.T) {
+	env := newTestEnv(&fakeConnection{lists: map[int]*entity.List{}})
+
+	res := serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/7/1", nil)
+	require.Equal(t, http.StatusNotFound, res.Code)
+	require.Equal(t, ContentTypeProblemJson, res.Header().Get("Content-Type"))
+
+	var problem problemDetails
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &problem))
+	require.Equal(t, "urn:xfsc:statuslist:problem:list-not-found", problem.Type)
+	require.Equal(t, http.StatusNotFound, problem.Status)
+	require.Equal(t, "/v1/tenants/tenant/status/7/1", problem.Instance)
+	require.NotEmpty(t, problem.Detail)
+	require.Equal(t, res.Header().Get(HeaderRequestId), problem.CorrelationId)
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/seven/1", nil)
+	require.Equal(t, http.StatusBadRequest, res.Code)
+	require.Contains(t, res.Body.String(), "invalid-request")
+}
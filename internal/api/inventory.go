@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/gin-gonic/gin"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// listInventoryItem describes a list of the tenant inventory.
+type listInventoryItem struct {
+	ListId int `json:"listId"`
+	entity.ListStatistics
+	Bits           int    `json:"bits"`
+	Purpose        string `json:"purpose"`
+	AllocationMode string `json:"allocationMode"`
+	Version        int64  `json:"version"`
+	LastModified   string `json:"lastModified"`
+}
+
+type inventorySummary struct {
+	Lists int `json:"lists"`
+	entity.ListStatistics
+	// Utilization is the share of allocated entries of all entries
+	Utilization float64 `json:"utilization"`
+}
+
+func (env *apienv) handleGetLists(ctx *gin.Context) {
+	tenantId := ctx.Param("tenantId")
+
+	offset, limit, ok := pageParams(ctx)
+	if !ok {
+		return
+	}
+
+	lists, total, err := env.db.GetStatusLists(ctx, tenantId, offset, limit)
+	if err != nil {
+		logger.Error("Error reading lists", err.Error())
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	items := make([]listInventoryItem, len(lists))
+	for i := range lists {
+		items[i] = newListInventoryItem(&lists[i])
+	}
+
+	summary, err := env.inventorySummary(ctx, tenantId)
+	if err != nil {
+		logger.Error("Error summarizing lists", err.Error())
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"tenantId": tenantId,
+		"offset":   offset,
+		"limit":    limit,
+		"total":    total,
+		"lists":    items,
+		"summary":  summary,
+	})
+}
+
+func newListInventoryItem(list *entity.List) listInventoryItem {
+	return listInventoryItem{
+		ListId:         list.ListId,
+		ListStatistics: list.Statistics(),
+		Bits:           list.Bits,
+		Purpose:        list.Purpose,
+		AllocationMode: list.AllocationMode,
+		Version:        list.Version,
+		LastModified:   list.LastUpdate.UTC().Format(time.RFC3339),
+	}
+}
+
+// inventorySummary sums up the statistics of all lists of the tenant. The lists are read page by page to bound the memory of large tenants.
+func (env *apienv) inventorySummary(ctx context.Context, tenantId string) (inventorySummary, error) {
+	var summary inventorySummary
+
+	for offset := 0; ; offset += maxPageSize {
+		lists, _, err := env.db.GetStatusLists(ctx, tenantId, offset, maxPageSize)
+		if err != nil {
+			return summary, err
+		}
+
+		for i := range lists {
+			summary.Lists++
+			summary.Add(lists[i].Statistics())
+		}
+
+		if len(lists) < maxPageSize {
+			break
+		}
+	}
+
+	if summary.Size > 0 {
+		summary.Utilization = float64(summary.Allocated) / float64(summary.Size)
+	}
+
+	return summary, nil
+}
+
+// pageParams reads the query parameters offset and limit of a paged listing. It answers invalid values with a problem and reports whether the handler can go on.
+func pageParams(ctx *gin.Context) (offset int, limit int, ok bool) {
+	offset, err := queryInt(ctx, "offset", 0)
+	if err != nil || offset < 0 {
+		abortWithProblem(ctx, fmt.Errorf("%w: invalid offset %s", errInvalidRequest, ctx.Query("offset")))
+		return 0, 0, false
+	}
+
+	limit, err = queryInt(ctx, "limit", defaultPageSize)
+	if err != nil || limit < 1 || limit > maxPageSize {
+		abortWithProblem(ctx, fmt.Errorf("%w: invalid limit %s", errInvalidRequest, ctx.Query("limit")))
+		return 0, 0, false
+	}
+
+	return offset, limit, true
+}
+
+func queryInt(ctx *gin.Context, name string, defaultValue int) (int, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// pathInt parses the path parameter name as a number.
+func pathInt(ctx *gin.Context, name string) (int, error) {
+	value, err := strconv.Atoi(ctx.Param(name))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s is not a number: %s", errInvalidRequest, name, ctx.Param(name))
+	}
+	return value, nil
+}
@@ -8,381 +8,560 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/cloudevents/sdk-go/v2/event"
 	cloudeventprovider "github.com/eclipse-xfsc/cloud-event-provider"
 	messaging "github.com/eclipse-xfsc/nats-message-library"
 	"github.com/eclipse-xfsc/nats-message-library/common"
-	"github.com/eclipse-xfsc/statuslist-service/internal/config"
 	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
-	"github.com/google/uuid"
+	"github.com/eclipse-xfsc/statuslist-service/internal/signer"
 	"github.com/klauspost/compress/gzip"
 	log "github.com/sirupsen/logrus"
 )
 
-var statusConf *config.StatusListConfiguration
+// CreateStatusListEntryRequest extends the library request by the list type, status size, allocation mode and purpose of the requested entry and its scheduled revocation.
+type CreateStatusListEntryRequest struct {
+	messaging.CreateStatusListEntryRequest
+	Type           string     `json:"type,omitempty"`
+	Bits           int        `json:"bits,omitempty"`
+	AllocationMode string     `json:"allocationMode,omitempty"`
+	Purpose        string     `json:"purpose,omitempty"`
+	RevokeAt       *time.Time `json:"revokeAt,omitempty"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	CredentialRef  string     `json:"credentialRef,omitempty"`
+}
 
-type VerifyCredentialPayload struct {
-	Credential []byte `json:"credential"`
+// CreateStatusListEntryReply extends the library reply by the list, the status size and the credential status of the allocated entry.
+type CreateStatusListEntryReply struct {
+	messaging.CreateStatusListEntryReply
+	ListId           int                    `json:"listId"`
+	Bits             int                    `json:"bits"`
+	CredentialStatus map[string]interface{} `json:"credentialStatus"`
 }
 
-func handle(ctx context.Context, event event.Event) (*event.Event, error) {
+// CreateStatusListEntriesRequest asks for Count entries with the same options in one transaction, or one entry per credential reference.
+type CreateStatusListEntriesRequest struct {
+	CreateStatusListEntryRequest
+	Count          int      `json:"count"`
+	CredentialRefs []string `json:"credentialRefs,omitempty"`
+}
 
-	if strings.Compare(event.Type(), "create") == 0 {
+type CreateStatusListEntriesReply struct {
+	common.Reply
+	Entries []*statusListEntry `json:"entries"`
+}
 
-		var eventData messaging.CreateStatusListEntryRequest
-		if err := json.Unmarshal(event.Data(), &eventData); err != nil {
-			log.Error(err)
-			return nil, err
-		}
+// ChangeStatusListEntryRequest addresses an entry of a list owned by this service whose status should change, either by list and index or by the reference of the credential. Actor, reason and comment are recorded in the audit log.
+type ChangeStatusListEntryRequest struct {
+	common.Request
+	ListId        int    `json:"listId"`
+	Index         int    `json:"index"`
+	CredentialRef string `json:"credentialRef,omitempty"`
+	Actor         string `json:"actor,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	Comment       string `json:"comment,omitempty"`
+}
 
-		log.Infof("new Event: %v", eventData)
+type ChangeStatusListEntryReply struct {
+	common.Reply
+	ListId int    `json:"listId"`
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+}
 
-		if err := db.CreateTableForTenantIdIfNotExists(ctx, eventData.TenantId); err != nil {
-			log.Error(err)
-			return nil, err
-		}
+// ReserveStatusListEntryRequest asks for an entry which is held for the issuance until it is committed, released or its ttl in seconds passes.
+type ReserveStatusListEntryRequest struct {
+	common.Request
+	Origin         string `json:"origin"`
+	Type           string `json:"type,omitempty"`
+	Bits           int    `json:"bits,omitempty"`
+	AllocationMode string `json:"allocationMode,omitempty"`
+	Purpose        string `json:"purpose,omitempty"`
+	Ttl            int    `json:"ttl,omitempty"`
+}
 
-		statusData, err := db.AllocateIndexInCurrentList(ctx, eventData.TenantId)
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
+type ReserveStatusListEntryReply struct {
+	common.Reply
+	*statusListReservation
+}
 
-		var commonError = new(common.Error)
+// ReservationRequest commits or releases a reservation. A commit may register the reference of the issued credential for the entry.
+type ReservationRequest struct {
+	common.Request
+	ReservationId string `json:"reservationId"`
+	CredentialRef string `json:"credentialRef,omitempty"`
+}
 
-		if err == nil {
-			commonError = nil
-		} else {
-			commonError.Id = uuid.NewString()
-			commonError.Status = 400
-			commonError.Msg = err.Error()
-		}
+type ReservationReply struct {
+	common.Reply
+	ReservationId string `json:"reservationId"`
+	ListId        int    `json:"listId"`
+	Index         int    `json:"index"`
+	Status        string `json:"status"`
+}
 
-		var rep = messaging.CreateStatusListEntryReply{
-			Reply: common.Reply{
-				TenantId:  eventData.TenantId,
-				RequestId: eventData.RequestId,
-				Error:     commonError,
-			},
-			Index:     statusData.Index,
-			StatusUrl: eventData.Origin + statusData.StatusUrl,
-			Purpose:   "revocation",
-			Type:      "StatusList2021",
-		}
+// VerifyStatusListEntryReply extends the library reply by the decoded status value of the entry.
+type VerifyStatusListEntryReply struct {
+	messaging.VerifyStatusListEntryReply
+	Status uint8 `json:"status"`
+	Bits   int   `json:"bits"`
+}
 
-		answerData, err := json.Marshal(rep)
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
+var errStatusListUnavailable = errors.New("status list could not be retrieved")
+var errInvalidStatusList = errors.New("status list is invalid")
 
-		answerEvent, err := cloudeventprovider.NewEvent("status-list-service", messaging.EventTypeStatus, answerData)
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
+func (env *apienv) handle(ctx context.Context, event event.Event) (*event.Event, error) {
 
-		return &answerEvent, nil
+	if strings.Compare(event.Type(), "create") == 0 {
+		return statusReply(env.handleCreateEvent(ctx, event.Data()))
 	}
 
-	if strings.Compare(event.Type(), "verify") == 0 {
-		var eventData messaging.VerifyStatusListEntryRequest
-		var err error
+	if strings.Compare(event.Type(), "createBatch") == 0 {
+		return statusReply(env.handleCreateBatchEvent(ctx, event.Data()))
+	}
 
-		if err = json.Unmarshal(event.Data(), &eventData); err != nil {
-			log.Error(err)
-			return nil, err
-		}
+	if strings.Compare(event.Type(), "suspend") == 0 || strings.Compare(event.Type(), "unsuspend") == 0 {
+		return statusReply(env.handleStatusChangeEvent(ctx, event.Type(), event.Data()))
+	}
 
-		log.Infof("new Event: %v", eventData)
+	if strings.Compare(event.Type(), "reserve") == 0 {
+		return statusReply(env.handleReserveEvent(ctx, event.Data()))
+	}
 
-		var commonError = new(common.Error)
+	if strings.Compare(event.Type(), "commit") == 0 || strings.Compare(event.Type(), "release") == 0 {
+		return statusReply(env.handleReservationEvent(ctx, event.Type(), event.Data()))
+	}
 
-		var request *http.Request
-		if eventData.Type == "StatusList2021" {
-			r, err := http.NewRequest("GET", eventData.StatusUrl, nil)
+	if strings.Compare(event.Type(), "verify") == 0 {
+		return statusReply(env.handleVerifyEvent(ctx, event.Data()))
+	}
 
-			if err != nil {
-				log.Error(err)
-				return nil, err
-			}
+	// the request fields are only used to correlate the reply
+	var request common.Request
+	_ = json.Unmarshal(event.Data(), &request)
 
-			r.Header.Add("Content-Type", "application/vc+ld+json")
-			request = r
-		}
+	return statusReply(common.Reply{
+		TenantId:  request.TenantId,
+		RequestId: request.RequestId,
+		Error:     failed(fmt.Errorf("%w: unsupported event type %s", errInvalidRequest, event.Type())),
+	})
+}
 
-		res, err := http.DefaultClient.Do(request)
+// statusReply wraps the reply into the event which answers the request.
+func statusReply(rep interface{}) (*event.Event, error) {
+	answerData, err := json.Marshal(rep)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
 
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
+	answerEvent, err := cloudeventprovider.NewEvent("status-list-service", messaging.EventTypeStatus, answerData)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
 
-		respBody, err := io.ReadAll(res.Body)
+	return &answerEvent, nil
+}
 
-		if err != nil {
-			log.Error(err)
-			return nil, err
+// failed logs err under the correlation id of the error reply which describes it.
+func failed(err error) *common.Error {
+	reply := replyError(err)
+	log.WithField("correlationId", reply.Id).Error(err)
+	return reply
+}
+
+func (env *apienv) handleCreateEvent(ctx context.Context, data []byte) CreateStatusListEntryReply {
+	var eventData CreateStatusListEntryRequest
+	if err := json.Unmarshal(data, &eventData); err != nil {
+		return CreateStatusListEntryReply{
+			CreateStatusListEntryReply: messaging.CreateStatusListEntryReply{
+				Reply: common.Reply{Error: failed(fmt.Errorf("%w: %w", errInvalidRequest, err))},
+			},
 		}
+	}
 
-		defer res.Body.Close()
+	log.Infof("new Event: %v", eventData)
 
-		if res.StatusCode != http.StatusOK {
-			return nil, errors.New("retrieve status list error. result was: " + string(respBody) + " " + res.Status)
-		}
+	var rep = CreateStatusListEntryReply{
+		CreateStatusListEntryReply: messaging.CreateStatusListEntryReply{
+			Reply: common.Reply{
+				TenantId:  eventData.TenantId,
+				RequestId: eventData.RequestId,
+			},
+		},
+	}
 
-		verPayload := VerifyCredentialPayload{Credential: respBody}
-		verPayloadBytes, err := json.Marshal(verPayload)
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
+	entry, err := env.createStatusListEntry(ctx, eventData.TenantId, statusListEntryRequest{
+		Origin:         eventData.Origin,
+		Type:           eventData.Type,
+		Bits:           eventData.Bits,
+		AllocationMode: eventData.AllocationMode,
+		Purpose:        eventData.Purpose,
+		RevokeAt:       eventData.RevokeAt,
+		ExpiresAt:      eventData.ExpiresAt,
+		CredentialRef:  eventData.CredentialRef,
+		IdempotencyKey: eventData.RequestId,
+	})
+	if err != nil {
+		rep.Error = failed(err)
+		return rep
+	}
 
-		req, err := http.NewRequest("POST", statusConf.SignerUrl+"/credential/verify", bytes.NewBuffer(verPayloadBytes))
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
-		req.Header.Add("Content-Type", "application/json")
-		req.Header.Add("x-namespace", eventData.TenantId)
-		req.Header.Add("x-group", eventData.GroupId)
-		verRes, err := http.DefaultClient.Do(req)
+	rep.Index = entry.Index
+	rep.StatusUrl = entry.StatusUrl
+	rep.Purpose = entry.Purpose
+	rep.Type = entry.Type
+	rep.ListId = entry.ListId
+	rep.Bits = entry.Bits
+	rep.CredentialStatus = entry.credentialStatus()
 
-		if err != nil {
-			log.Error(err)
-			return nil, err
+	return rep
+}
+
+func (env *apienv) handleCreateBatchEvent(ctx context.Context, data []byte) CreateStatusListEntriesReply {
+	var eventData CreateStatusListEntriesRequest
+	if err := json.Unmarshal(data, &eventData); err != nil {
+		return CreateStatusListEntriesReply{
+			Reply: common.Reply{Error: failed(fmt.Errorf("%w: %w", errInvalidRequest, err))},
 		}
+	}
 
-		verRespBody, err := io.ReadAll(verRes.Body)
+	log.Infof("new Event: %v", eventData)
 
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
+	var rep = CreateStatusListEntriesReply{
+		Reply: common.Reply{
+			TenantId:  eventData.TenantId,
+			RequestId: eventData.RequestId,
+		},
+	}
 
-		defer res.Body.Close()
+	entries, err := env.createStatusListEntries(ctx, eventData.TenantId, statusListEntryRequest{
+		Origin:         eventData.Origin,
+		Type:           eventData.Type,
+		Bits:           eventData.Bits,
+		AllocationMode: eventData.AllocationMode,
+		Purpose:        eventData.Purpose,
+		RevokeAt:       eventData.RevokeAt,
+		ExpiresAt:      eventData.ExpiresAt,
+		CredentialRef:  eventData.CredentialRef,
+		IdempotencyKey: eventData.RequestId,
+	}, eventData.Count, eventData.CredentialRefs)
+	if err != nil {
+		rep.Error = failed(err)
+		return rep
+	}
 
-		if res.StatusCode != http.StatusOK {
-			return nil, errors.New("signer service call error. result was: " + string(respBody) + " " + res.Status)
-		}
+	rep.Entries = entries
 
-		var result map[string]interface{}
-		err = json.Unmarshal(verRespBody, &result)
+	return rep
+}
 
-		if err != nil {
-			log.Error(err)
-			return nil, err
+func (env *apienv) handleStatusChangeEvent(ctx context.Context, eventType string, data []byte) ChangeStatusListEntryReply {
+	var eventData ChangeStatusListEntryRequest
+	if err := json.Unmarshal(data, &eventData); err != nil {
+		return ChangeStatusListEntryReply{
+			Reply: common.Reply{Error: failed(fmt.Errorf("%w: %w", errInvalidRequest, err))},
 		}
+	}
 
-		valid, ok := result["valid"]
+	log.Infof("new Event: %v", eventData)
 
-		if ok && !valid.(bool) {
-			log.Error(err)
-			return nil, err
-		}
+	change, status := env.db.SuspendCredentialInSpecifiedList, "suspended"
+	if strings.Compare(eventType, "unsuspend") == 0 {
+		change, status = env.db.UnsuspendCredentialInSpecifiedList, "valid"
+	}
 
-		if !ok {
-			log.Error(err)
-			return nil, err
-		}
+	var rep = ChangeStatusListEntryReply{
+		Reply: common.Reply{
+			TenantId:  eventData.TenantId,
+			RequestId: eventData.RequestId,
+		},
+		ListId: eventData.ListId,
+		Index:  eventData.Index,
+	}
 
-		var cred map[string]interface{}
-		err = json.Unmarshal(respBody, &cred)
+	reason := entity.ChangeReason{
+		Actor:     eventData.Actor,
+		Reason:    eventData.Reason,
+		Comment:   eventData.Comment,
+		RequestId: eventData.RequestId,
+	}
 
+	if err := reason.Validate(); err != nil {
+		rep.Error = failed(err)
+		return rep
+	}
+
+	if eventData.CredentialRef != "" {
+		entry, err := env.db.GetCredentialEntry(ctx, eventData.TenantId, eventData.CredentialRef)
 		if err != nil {
-			log.Error(err)
-			return nil, err
+			rep.Error = failed(err)
+			return rep
 		}
+		rep.ListId, rep.Index = entry.ListId, entry.Index
+	}
 
-		val, ok := cred["credentialSubject"].(map[string]interface{})
+	if err := change(ctx, eventData.TenantId, rep.ListId, rep.Index, reason); err != nil {
+		rep.Error = failed(err)
+		return rep
+	}
 
-		if !ok {
-			log.Error(err)
-			return nil, err
-		}
+	env.signedLists.Invalidate(eventData.TenantId, rep.ListId)
+	rep.Status = status
 
-		l, ok := val["encodedList"].(string)
+	return rep
+}
 
-		if !ok {
-			return nil, err
+func (env *apienv) handleReserveEvent(ctx context.Context, data []byte) ReserveStatusListEntryReply {
+	var eventData ReserveStatusListEntryRequest
+	if err := json.Unmarshal(data, &eventData); err != nil {
+		return ReserveStatusListEntryReply{
+			Reply: common.Reply{Error: failed(fmt.Errorf("%w: %w", errInvalidRequest, err))},
 		}
+	}
 
-		l2, err := base64.RawStdEncoding.DecodeString(l)
+	log.Infof("new Event: %v", eventData)
 
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
+	var rep = ReserveStatusListEntryReply{
+		Reply: common.Reply{
+			TenantId:  eventData.TenantId,
+			RequestId: eventData.RequestId,
+		},
+	}
 
-		reader := bytes.NewReader([]byte(l2))
-		gzreader, err := gzip.NewReader(reader)
+	reservation, err := env.reserveStatusListEntry(ctx, eventData.TenantId, statusListReservationRequest{
+		Origin:         eventData.Origin,
+		Type:           eventData.Type,
+		Bits:           eventData.Bits,
+		AllocationMode: eventData.AllocationMode,
+		Purpose:        eventData.Purpose,
+		Ttl:            eventData.Ttl,
+	})
+	if err != nil {
+		rep.Error = failed(err)
+		return rep
+	}
 
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
+	rep.statusListReservation = reservation
 
-		blist, err := io.ReadAll(gzreader)
+	return rep
+}
 
-		if err != nil {
-			log.Error(err)
-			return nil, err
+func (env *apienv) handleReservationEvent(ctx context.Context, eventType string, data []byte) ReservationReply {
+	var eventData ReservationRequest
+	if err := json.Unmarshal(data, &eventData); err != nil {
+		return ReservationReply{
+			Reply: common.Reply{Error: failed(fmt.Errorf("%w: %w", errInvalidRequest, err))},
 		}
+	}
 
-		url, _ := url.Parse(eventData.StatusUrl)
+	log.Infof("new Event: %v", eventData)
 
-		sha256 := sha256.New()
-		cacheId := hex.EncodeToString(sha256.Sum([]byte(url.Host)))
+	var rep = ReservationReply{
+		Reply: common.Reply{
+			TenantId:  eventData.TenantId,
+			RequestId: eventData.RequestId,
+		},
+		ReservationId: eventData.ReservationId,
+	}
 
-		if err = db.CacheList(ctx, cacheId, blist); err != nil {
-			log.Error(err)
-			return nil, err
-		}
+	end, status := func() (*entity.Reservation, error) {
+		return env.db.ReleaseReservation(ctx, eventData.TenantId, eventData.ReservationId)
+	}, "released"
+	if strings.Compare(eventType, "commit") == 0 {
+		end, status = func() (*entity.Reservation, error) {
+			return commitReservation(ctx, env.db, eventData.TenantId, eventData.ReservationId, eventData.CredentialRef)
+		}, "committed"
+	}
 
-		list := entity.List{
-			List: blist,
-		}
+	reservation, err := end()
+	if err != nil {
+		rep.Error = failed(err)
+		return rep
+	}
+
+	rep.ListId = reservation.ListId
+	rep.Index = reservation.Index
+	rep.Status = status
+
+	return rep
+}
 
-		if err == nil {
-			commonError = nil
-		} else {
-			commonError.Id = uuid.NewString()
-			commonError.Status = 400
-			commonError.Msg = err.Error()
+func (env *apienv) handleVerifyEvent(ctx context.Context, data []byte) VerifyStatusListEntryReply {
+	var eventData messaging.VerifyStatusListEntryRequest
+	if err := json.Unmarshal(data, &eventData); err != nil {
+		return VerifyStatusListEntryReply{
+			VerifyStatusListEntryReply: messaging.VerifyStatusListEntryReply{
+				Reply: common.Reply{Error: failed(fmt.Errorf("%w: %w", errInvalidRequest, err))},
+			},
 		}
+	}
 
-		var rep = messaging.VerifyStatusListEntryReply{
+	log.Infof("new Event: %v", eventData)
+
+	var rep = VerifyStatusListEntryReply{
+		VerifyStatusListEntryReply: messaging.VerifyStatusListEntryReply{
 			Reply: common.Reply{
 				TenantId:  eventData.TenantId,
 				RequestId: eventData.RequestId,
-				Error:     commonError,
 			},
-			Revocated: list.CheckBitAtIndex(eventData.Index),
-			Suspended: false,
-		}
-
-		answerData, err := json.Marshal(rep)
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
+		},
+	}
 
-		answerEvent, err := cloudeventprovider.NewEvent("status-list-service", messaging.EventTypeStatus, answerData)
-		if err != nil {
-			log.Error(err)
-			return nil, err
-		}
+	list, err := env.fetchStatusList(ctx, eventData)
+	if err != nil {
+		rep.Error = failed(err)
+		return rep
+	}
 
-		return &answerEvent, nil
+	status, err := list.StatusAtIndex(eventData.Index)
+	if err != nil {
+		rep.Error = failed(err)
+		return rep
 	}
 
-	return nil, errors.ErrUnsupported
+	// both lookups only fail for an index out of range, which the status already reported
+	rep.Revocated, _ = list.IsRevoked(eventData.Index)
+	rep.Suspended, _ = list.IsSuspended(eventData.Index)
+	rep.Status = status
+	rep.Bits = list.Bits
+
+	return rep
 }
 
-func startMessaging(conf *config.StatusListConfiguration, group *sync.WaitGroup) {
-	defer group.Done()
-	statusConf = conf
-	client, err := cloudeventprovider.New(
-		cloudeventprovider.Config{Protocol: cloudeventprovider.ProtocolTypeNats, Settings: conf.Nats},
-		cloudeventprovider.ConnectionTypeRep,
-		conf.CreationTopic,
-	)
+// fetchStatusList retrieves the status list credential of the entry, lets the signer service verify it and caches the decoded list.
+func (env *apienv) fetchStatusList(ctx context.Context, eventData messaging.VerifyStatusListEntryRequest) (*entity.List, error) {
+	if eventData.Type != ListTypeStatusList2021 && eventData.Type != ListTypeBitstring {
+		return nil, fmt.Errorf("%w: %s", errUnknownListType, eventData.Type)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "GET", eventData.StatusUrl, nil)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("%w: %w", errInvalidRequest, err)
 	}
 
-	defer client.Close()
+	request.Header.Add("Accept", ContentTypeVcLdJson)
 
-	err = client.ReplyCtx(context.Background(), handle)
+	res, err := env.client.Do(request)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("%w: %w", errStatusListUnavailable, err)
 	}
-}
 
-func requestTokenSigning(tenantId, statusList, key, namespace, group, did, host string, bits, listId int) ([]byte, error) {
-
-	client, err := cloudeventprovider.New(cloudeventprovider.Config{
-		Protocol: cloudeventprovider.ProtocolTypeNats,
-		Settings: cloudeventprovider.NatsConfig{
-			Url:          config.CurrentStatusListConfig.Nats.Url,
-			QueueGroup:   config.CurrentStatusListConfig.Nats.QueueGroup,
-			TimeoutInSec: config.CurrentStatusListConfig.Nats.TimeoutInSec,
-		},
-	}, cloudeventprovider.Req, config.CurrentStatusListConfig.SignerTopic)
+	defer res.Body.Close()
 
+	respBody, err := io.ReadAll(res.Body)
 	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errStatusListUnavailable, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s %s", errStatusListUnavailable, res.Status, string(respBody))
+	}
+
+	key := signer.KeyRef{TenantId: eventData.TenantId, Namespace: eventData.TenantId, Group: eventData.GroupId}
+	if err := env.verifier.VerifyProof(ctx, key, respBody); err != nil {
+		if errors.Is(err, signer.ErrInvalidProof) {
+			return nil, fmt.Errorf("%w: %w", errInvalidStatusList, err)
+		}
 		return nil, err
 	}
 
-	var list = make(map[string]interface{})
+	var cred map[string]interface{}
+	if err := json.Unmarshal(respBody, &cred); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidStatusList, err)
+	}
 
-	list["bits"] = bits
-	list["lst"] = statusList
+	val, ok := cred["credentialSubject"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: credentialSubject missing", errInvalidStatusList)
+	}
 
-	var p = make(map[string]interface{})
+	l, ok := val["encodedList"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: encodedList missing", errInvalidStatusList)
+	}
 
-	//https://www.ietf.org/archive/id/draft-looker-oauth-jwt-cwt-status-list-01.html#section-4.2
-	p["status_list"] = list
-	p["iss"] = host
-	p["sub"] = host + "/statuslists/" + strconv.Itoa(listId)
-	p["iat"] = time.Now().UnixMilli()
-	p["exp"] = time.Now().Add(time.Duration(time.Now().Year())).UnixMilli()
-	pb, err := json.Marshal(p)
+	var l2 []byte
+	if eventData.Type == ListTypeBitstring {
+		// multibase base64url with the prefix u
+		l2, err = base64.RawURLEncoding.DecodeString(strings.TrimPrefix(l, "u"))
+	} else {
+		l2, err = base64.RawStdEncoding.DecodeString(l)
+	}
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", errInvalidStatusList, err)
 	}
 
-	var ph = make(map[string]interface{})
-	ph["kid"] = did + "#" + key
-	pbh, err := json.Marshal(ph)
-
+	gzreader, err := gzip.NewReader(bytes.NewReader(l2))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", errInvalidStatusList, err)
 	}
 
-	payload := messaging.CreateTokenRequest{
-		Request: common.Request{
-			TenantId:  tenantId,
-			RequestId: uuid.NewString(),
-		},
-		Namespace: namespace,
-		Group:     group,
-		Key:       key,
-		Payload:   pb,
-		Header:    pbh,
+	blist, err := io.ReadAll(gzreader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidStatusList, err)
 	}
 
-	b, err := json.Marshal(payload)
+	list := entity.List{
+		List:    blist,
+		Bits:    entity.DefaultBits,
+		Purpose: entity.PurposeRevocation,
+	}
 
-	if err != nil {
-		return nil, err
+	if purpose, ok := val["statusPurpose"].(string); ok && purpose != entity.PurposeMessage {
+		list.Purpose = purpose
 	}
 
-	event, err := cloudeventprovider.NewEvent("statuslist-service", messaging.SignerServiceSignTokenType, b)
+	if statusSize, ok := val["statusSize"].(float64); ok {
+		if statusSize != float64(int(statusSize)) || !entity.ValidBits(int(statusSize)) {
+			return nil, fmt.Errorf("%w: statusSize %v is not a supported status size", errInvalidStatusList, statusSize)
+		}
+		list.Bits = int(statusSize)
+	}
 
-	if err != nil {
-		return nil, err
+	if eventData.Type == ListTypeBitstring {
+		if list.List, err = entity.DecodeBitstring(blist, list.Bits); err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidStatusList, err)
+		}
 	}
 
-	rep, err := client.RequestCtx(context.Background(), event)
+	url, _ := url.Parse(eventData.StatusUrl)
 
-	if err != nil {
+	digest := sha256.Sum256([]byte(url.Host))
+	cacheId := hex.EncodeToString(digest[:])
+
+	if err = env.db.CacheList(ctx, cacheId, list.List); err != nil {
 		return nil, err
 	}
 
-	var tok messaging.CreateTokenReply
+	return &list, nil
+}
 
-	err = json.Unmarshal(rep.Data(), &tok)
+func startMessaging(env *apienv) {
+	conf := env.conf
+	client, err := cloudeventprovider.New(
+		cloudeventprovider.Config{Protocol: cloudeventprovider.ProtocolTypeNats, Settings: conf.Nats},
+		cloudeventprovider.ConnectionTypeRep,
+		conf.CreationTopic,
+	)
+	if err != nil {
+		panic(err)
+	}
 
-	return tok.Token, err
+	defer client.Close()
 
+	err = client.ReplyCtx(context.Background(), func(ctx context.Context, event event.Event) (*event.Event, error) {
+		return env.handle(ctx, event)
+	})
+	if err != nil {
+		panic(err)
+	}
 }
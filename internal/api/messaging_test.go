@@ -0,0 +1,215 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cloudeventprovider "github.com/eclipse-xfsc/cloud-event-provider"
+	messaging "github.com/eclipse-xfsc/nats-message-library"
+	"github.com/eclipse-xfsc/nats-message-library/common"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/eclipse-xfsc/statuslist-service/internal/signer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEventReplyError(t *testing.T) {
+	env := newTestEnv(&fakeConnection{})
+
+	request, err := json.Marshal(CreateStatusListEntryRequest{
+		CreateStatusListEntryRequest: messaging.CreateStatusListEntryRequest{
+			Request: common.Request{TenantId: "tenant", RequestId: "42"},
+		},
+		Bits: 3,
+	})
+	require.NoError(t, err)
+
+	rep := env.handleCreateEvent(context.Background(), request)
+	require.Equal(t, "42", rep.RequestId)
+	require.NotNil(t, rep.Error)
+	require.Equal(t, http.StatusUnprocessableEntity, rep.Error.Status)
+	require.Contains(t, rep.Error.Msg, "validation-failed")
+	require.NotEmpty(t, rep.Error.Id)
+
+	rep = env.handleCreateEvent(context.Background(), []byte("{"))
+	require.NotNil(t, rep.Error)
+	require.Equal(t, http.StatusBadRequest, rep.Error.Status)
+}
+
+func TestUnsupportedEventReplyError(t *testing.T) {
+	data, err := json.Marshal(common.Request{TenantId: "tenant", RequestId: "42"})
+	require.NoError(t, err)
+	request, err := cloudeventprovider.NewEvent("test", "delete", data)
+	require.NoError(t, err)
+
+	answer, err := newTestEnv(&fakeConnection{}).handle(context.Background(), request)
+	require.NoError(t, err)
+
+	var rep common.Reply
+	require.NoError(t, json.Unmarshal(answer.Data(), &rep))
+	require.Equal(t, "42", rep.RequestId)
+	require.NotNil(t, rep.Error)
+	require.Equal(t, http.StatusBadRequest, rep.Error.Status)
+	require.Contains(t, rep.Error.Msg, "invalid-request")
+}
+
+func TestVerifyEventReplyError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	env := newTestEnv(&fakeConnection{})
+	env.client = server.Client()
+
+	verify := func(listType string) VerifyStatusListEntryReply {
+		var request messaging.VerifyStatusListEntryRequest
+		request.TenantId = "tenant"
+		request.RequestId = "42"
+		request.StatusUrl = server.URL
+		request.Type = listType
+		data, err := json.Marshal(request)
+		require.NoError(t, err)
+		return env.handleVerifyEvent(context.Background(), data)
+	}
+
+	rep := verify(ListTypeBitstring)
+	require.Equal(t, "42", rep.RequestId)
+	require.NotNil(t, rep.Error)
+	require.Equal(t, http.StatusBadGateway, rep.Error.Status)
+	require.Contains(t, rep.Error.Msg, "status-list-unavailable")
+
+	rep = verify(ListTypeTokenStatusList)
+	require.NotNil(t, rep.Error)
+	require.Equal(t, http.StatusUnprocessableEntity, rep.Error.Status)
+}
+
+func TestVerifyEventBitstringOrder(t *testing.T) {
+	env := newTestEnv(&fakeConnection{})
+
+	list := entity.NewListWithOptions(2, entity.ListOptions{Bits: 2, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	_, err := list.AllocateIndices(6)
+	require.NoError(t, err)
+	require.NoError(t, list.SuspendAtIndex(5))
+
+	compressed, err := compressGzip(list.Bitstring())
+	require.NoError(t, err)
+	credential := buildCredentialBitstring(env.conf, "tenant", "u"+base64.RawURLEncoding.EncodeToString(compressed), "did:web:issuer", "https://issuer.example", "1", list)
+	require.Equal(t, entity.PurposeMessage, credential["credentialSubject"].(map[string]interface{})["statusPurpose"])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"valid": true}`))
+			return
+		}
+		json.NewEncoder(w).Encode(credential)
+	}))
+	defer server.Close()
+
+	env.verifier = signer.NewHttp(server.URL, signer.Options{})
+	env.client = server.Client()
+
+	verify := func(index int) VerifyStatusListEntryReply {
+		var request messaging.VerifyStatusListEntryRequest
+		request.TenantId = "tenant"
+		request.StatusUrl = server.URL
+		request.Type = ListTypeBitstring
+		request.Index = index
+		data, err := json.Marshal(request)
+		require.NoError(t, err)
+		return env.handleVerifyEvent(context.Background(), data)
+	}
+
+	rep := verify(5)
+	require.Nil(t, rep.Error)
+	require.Equal(t, entity.StatusSuspended, rep.Status)
+	require.True(t, rep.Suspended)
+
+	rep = verify(2)
+	require.Nil(t, rep.Error)
+	require.Equal(t, entity.StatusValid, rep.Status)
+}
+
+func TestVerifyEventWithLocalSigner(t *testing.T) {
+	env := newTestEnv(&fakeConnection{})
+
+	list := entity.NewList(1, 1)
+	_, err := list.AllocateIndices(2)
+	require.NoError(t, err)
+	require.NoError(t, list.RevokeAtIndex(1))
+
+	compressed, err := compressGzip(list.Bitstring())
+	require.NoError(t, err)
+	credential := buildCredentialBitstring(env.conf, "tenant", "u"+base64.RawURLEncoding.EncodeToString(compressed), "did:web:issuer", "https://issuer.example", "1", list)
+
+	var verified http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			verified = r.Header
+			w.Write([]byte(`{"valid": true}`))
+			return
+		}
+		json.NewEncoder(w).Encode(credential)
+	}))
+	defer server.Close()
+
+	// the tenant signs with the local signer, which can not verify proofs of other issuers
+	env.signer = signer.NewLocal(t.TempDir())
+	env.verifier = signer.NewHttp(server.URL, signer.Options{})
+	env.client = server.Client()
+
+	var request messaging.VerifyStatusListEntryRequest
+	request.TenantId = "tenant"
+	request.GroupId = "group"
+	request.StatusUrl = server.URL
+	request.Type = ListTypeBitstring
+	request.Index = 1
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	rep := env.handleVerifyEvent(context.Background(), data)
+	require.Nil(t, rep.Error)
+	require.True(t, rep.Revocated)
+	require.Equal(t, "tenant", verified.Get("x-namespace"))
+	require.Equal(t, "group", verified.Get("x-group"))
+}
+
+func TestVerifyEventRejectsInvalidStatusSize(t *testing.T) {
+	env := newTestEnv(&fakeConnection{})
+
+	list := entity.NewListWithOptions(2, entity.ListOptions{Bits: 2, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	compressed, err := compressGzip(list.Bitstring())
+	require.NoError(t, err)
+	credential := buildCredentialBitstring(env.conf, "tenant", "u"+base64.RawURLEncoding.EncodeToString(compressed), "did:web:issuer", "https://issuer.example", "1", list)
+	subject := credential["credentialSubject"].(map[string]interface{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"valid": true}`))
+			return
+		}
+		json.NewEncoder(w).Encode(credential)
+	}))
+	defer server.Close()
+
+	env.verifier = signer.NewHttp(server.URL, signer.Options{})
+	env.client = server.Client()
+
+	for _, statusSize := range []interface{}{0, -2, 3, 2.5, 16} {
+		subject["statusSize"] = statusSize
+
+		var request messaging.VerifyStatusListEntryRequest
+		request.TenantId = "tenant"
+		request.StatusUrl = server.URL
+		request.Type = ListTypeBitstring
+		data, err := json.Marshal(request)
+		require.NoError(t, err)
+
+		rep := env.handleVerifyEvent(context.Background(), data)
+		require.NotNil(t, rep.Error, statusSize)
+		require.Contains(t, rep.Error.Msg, "status-list-invalid", statusSize)
+	}
+}
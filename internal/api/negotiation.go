@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Content types in which a status list can be served.
+const (
+	ContentTypeStatusListJwt = "application/statuslist+jwt"
+	ContentTypeStatusListCwt = "application/statuslist+cwt"
+	ContentTypeVcLdJson      = "application/vc+ld+json"
+	ContentTypeVcJwt         = "application/vc+jwt"
+	ContentTypeJson          = "application/json"
+	ContentTypeOctetStream   = "application/octet-stream"
+)
+
+// listContentTypes are offered in order of preference, the first one is served for */*.
+var listContentTypes = []string{
+	ContentTypeJson,
+	ContentTypeStatusListJwt,
+	ContentTypeStatusListCwt,
+	ContentTypeVcLdJson,
+	ContentTypeVcJwt,
+	ContentTypeOctetStream,
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// negotiateListContentType selects the content type of a status list response. Without Accept header or with */* only, which most clients send by default, the Content-Type header decides as in earlier versions of the service. It returns "" if no offered content type is acceptable.
+func negotiateListContentType(header http.Header) string {
+	accept := header.Get("Accept")
+	wildcard := acceptsAnything(accept)
+
+	if accept == "" || wildcard {
+		cty := strings.ToLower(strings.TrimSpace(strings.Split(header.Get("Content-Type"), ";")[0]))
+		if cty == "" {
+			return ContentTypeJson
+		}
+		if cty == "statuslist+jwt" {
+			return ContentTypeStatusListJwt
+		}
+		// a client accepting anything gets the default for content types which are not offered
+		if legacy := negotiateContentType(cty, listContentTypes); legacy != "" || !wildcard {
+			return legacy
+		}
+	}
+
+	return negotiateContentType(accept, listContentTypes)
+}
+
+// acceptsAnything reports whether the accept header only consists of */* ranges.
+func acceptsAnything(accept string) bool {
+	ranges := parseAccept(accept)
+	for _, r := range ranges {
+		if r.mediaType != "*/*" || r.q == 0 {
+			return false
+		}
+	}
+	return len(ranges) > 0
+}
+
+// negotiateContentType returns the offer with the highest quality in the accept header as defined by RFC 9110 section 12.5.1. Ties are resolved by the order of the offers.
+func negotiateContentType(accept string, offers []string) string {
+	ranges := parseAccept(accept)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q := offerQuality(offer, ranges)
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// offerQuality returns the quality of the most specific media range matching the offer.
+func offerQuality(offer string, ranges []mediaRange) float64 {
+	offerType := strings.Split(offer, "/")[0]
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch r.mediaType {
+		case offer:
+			s = 2
+		case offerType + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		}
+
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+
+	return q
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+
+		r := mediaRange{mediaType: mediaType, q: 1}
+		for _, param := range params[1:] {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.ToLower(strings.TrimSpace(name)) != "q" {
+				continue
+			}
+
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || q < 0 || q > 1 {
+				q = 0
+			}
+			r.q = q
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges
+}
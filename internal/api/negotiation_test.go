@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"application/statuslist+jwt", ContentTypeStatusListJwt},
+		{"application/statuslist+cwt", ContentTypeStatusListCwt},
+		{"*/*", ContentTypeJson},
+		{"application/*", ContentTypeJson},
+		{"application/vc+ld+json;q=0.5, application/statuslist+jwt;q=0.9", ContentTypeStatusListJwt},
+		{"application/vc+jwt, application/*;q=0.1", ContentTypeVcJwt},
+		{"application/*;q=0.5, application/json;q=0", ContentTypeStatusListJwt},
+		{"text/html", ""},
+		{"application/statuslist+jwt;q=0", ""},
+		{"APPLICATION/OCTET-STREAM", ContentTypeOctetStream},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, negotiateContentType(test.accept, listContentTypes), test.accept)
+	}
+}
+
+func TestNegotiateListContentTypeWithoutAccept(t *testing.T) {
+	require.Equal(t, ContentTypeJson, negotiateListContentType(http.Header{}))
+
+	header := http.Header{}
+	header.Set("Content-Type", "statuslist+jwt")
+	require.Equal(t, ContentTypeStatusListJwt, negotiateListContentType(header))
+
+	header.Set("Content-Type", "application/vc+ld+json; charset=utf-8")
+	require.Equal(t, ContentTypeVcLdJson, negotiateListContentType(header))
+
+	header.Set("Accept", "application/statuslist+cwt")
+	require.Equal(t, ContentTypeStatusListCwt, negotiateListContentType(header))
+}
+
+func TestNegotiateListContentTypeWithWildcardAccept(t *testing.T) {
+	header := http.Header{}
+	header.Set("Accept", "*/*")
+	header.Set("Content-Type", "application/statuslist+jwt")
+	require.Equal(t, ContentTypeStatusListJwt, negotiateListContentType(header))
+
+	header.Set("Accept", "*/*;q=0.8")
+	header.Set("Content-Type", "statuslist+jwt")
+	require.Equal(t, ContentTypeStatusListJwt, negotiateListContentType(header))
+
+	header.Set("Content-Type", "text/plain")
+	require.Equal(t, ContentTypeJson, negotiateListContentType(header))
+
+	header.Del("Content-Type")
+	require.Equal(t, ContentTypeJson, negotiateListContentType(header))
+
+	header.Set("Accept", "*/*;q=0.1, application/statuslist+cwt")
+	header.Set("Content-Type", "application/statuslist+jwt")
+	require.Equal(t, ContentTypeStatusListCwt, negotiateListContentType(header))
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/database"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/gin-gonic/gin"
+	logger "github.com/sirupsen/logrus"
+)
+
+var errInvalidTtl = errors.New("invalid reservation ttl")
+
+// statusListReservationRequest asks for an entry which is held for an issuance until it is committed or released.
+type statusListReservationRequest struct {
+	Origin         string `json:"origin"`
+	Type           string `json:"type,omitempty"`
+	Bits           int    `json:"bits,omitempty"`
+	AllocationMode string `json:"allocationMode,omitempty"`
+	Purpose        string `json:"purpose,omitempty"`
+	// Ttl is the lifetime of the reservation in seconds, zero selects the configured default
+	Ttl int `json:"ttl,omitempty"`
+}
+
+// statusListReservation is a reserved entry which must be committed before it expires.
+type statusListReservation struct {
+	ReservationId string    `json:"reservationId"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	statusListEntry
+}
+
+// commitReservationRequest is the optional body of a commit, it registers the reference of the issued credential for the entry.
+type commitReservationRequest struct {
+	CredentialRef string `json:"credentialRef,omitempty"`
+}
+
+// reserveStatusListEntry reserves an entry for the tenant in a list matching the request and the configured defaults. The index is handed out to nobody else until the reservation ends.
+func (env *apienv) reserveStatusListEntry(ctx context.Context, tenantId string, request statusListReservationRequest) (*statusListReservation, error) {
+	c := env.conf
+	ttl := c.ReservationTtl
+	if request.Ttl != 0 {
+		ttl = time.Duration(request.Ttl) * time.Second
+	}
+
+	if ttl <= 0 || ttl > c.ReservationMaxTtl {
+		return nil, fmt.Errorf("%w: %s not between 1s and %s", errInvalidTtl, ttl, c.ReservationMaxTtl)
+	}
+
+	template, err := newEntryTemplate(c, tenantId, statusListEntryRequest{
+		Origin:         request.Origin,
+		Type:           request.Type,
+		Bits:           request.Bits,
+		AllocationMode: request.AllocationMode,
+		Purpose:        request.Purpose,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := env.db.CreateTableForTenantIdIfNotExists(ctx, tenantId); err != nil {
+		return nil, err
+	}
+
+	reservation, err := env.db.ReserveIndexInCurrentList(ctx, tenantId, template.options, time.Now().Add(ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	env.signedLists.Invalidate(tenantId, reservation.ListId)
+
+	return &statusListReservation{
+		ReservationId:   reservation.Id,
+		ExpiresAt:       reservation.ExpiresAt,
+		statusListEntry: *template.entry(entity.NewStatusData(reservation.Index, reservation.ListId)),
+	}, nil
+}
+
+// commitReservation keeps the reserved entry allocated and registers the credential reference for it.
+func commitReservation(ctx context.Context, database *database.Database, tenantId string, id string, credentialRef string) (*entity.Reservation, error) {
+	var allocation entity.Allocation
+	if credentialRef != "" {
+		if err := entity.ValidateCredentialRef(credentialRef); err != nil {
+			return nil, err
+		}
+		allocation.CredentialRefs = []string{credentialRef}
+	}
+
+	return database.CommitReservation(ctx, tenantId, id, time.Now(), allocation)
+}
+
+func (env *apienv) handleReserveEntry(ctx *gin.Context) {
+	tenantId := ctx.Param("tenantId")
+
+	var request statusListReservationRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		abortWithProblem(ctx, fmt.Errorf("%w: %w", errInvalidRequest, err))
+		return
+	}
+
+	reservation, err := env.reserveStatusListEntry(ctx, tenantId, request)
+	if err != nil {
+		logger.Error("Error reserving status list entry", err.Error())
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, reservation)
+}
+
+func (env *apienv) handleCommitReservation(ctx *gin.Context) {
+	tenantId := ctx.Param("tenantId")
+
+	var request commitReservationRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		abortWithProblem(ctx, fmt.Errorf("%w: %w", errInvalidRequest, err))
+		return
+	}
+
+	reservation, err := commitReservation(ctx, env.db, tenantId, ctx.Param("reservationId"), request.CredentialRef)
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"tenantId":      tenantId,
+		"reservationId": reservation.Id,
+		"listId":        reservation.ListId,
+		"index":         reservation.Index,
+		"credentialRef": request.CredentialRef,
+		"status":        "committed",
+	})
+}
+
+func (env *apienv) handleReleaseReservation(ctx *gin.Context) {
+	tenantId := ctx.Param("tenantId")
+
+	reservation, err := env.db.ReleaseReservation(ctx, tenantId, ctx.Param("reservationId"))
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"tenantId":      tenantId,
+		"reservationId": reservation.Id,
+		"listId":        reservation.ListId,
+		"index":         reservation.Index,
+		"status":        "released",
+	})
+}
+
+// releaseExpiredReservations returns the indices of expired reservations to their lists in batches of limit until none is left.
+func releaseExpiredReservations(ctx context.Context, database *database.Database, limit int) error {
+	for {
+		reservations, err := database.ReleaseExpiredReservations(ctx, time.Now(), limit)
+		if err != nil {
+			return err
+		}
+
+		if len(reservations) > 0 {
+			logger.Infof("Released %d expired reservations", len(reservations))
+		}
+
+		if len(reservations) < limit {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/nats-message-library/common"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveCommitRelease(t *testing.T) {
+	list := entity.NewListWithOptions(1, entity.ListOptions{Bits: 1, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	list.ListId = 1
+	env := newTestEnv(&fakeConnection{lists: map[int]*entity.List{1: list}, reservations: map[string]*entity.Reservation{}})
+
+	reserve := func() statusListReservation {
+		res := serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/reservations", nil)
+		require.Equal(t, http.StatusCreated, res.Code)
+
+		var reservation statusListReservation
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &reservation))
+		require.NotEmpty(t, reservation.ReservationId)
+		require.NotEmpty(t, reservation.CredentialStatus)
+		require.WithinDuration(t, time.Now().Add(time.Minute), reservation.ExpiresAt, time.Second)
+		return reservation
+	}
+
+	reservation := reserve()
+	require.Equal(t, 0, reservation.Index)
+	require.Equal(t, 7, list.Free)
+
+	res := serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/reservations/"+reservation.ReservationId+"/release", nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, 8, list.Free)
+
+	// the released index is reserved again
+	reservation = reserve()
+	require.Equal(t, 0, reservation.Index)
+
+	res = serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/reservations/"+reservation.ReservationId+"/commit", commitReservationRequest{CredentialRef: "urn:uuid:42"})
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Contains(t, res.Body.String(), `"committed"`)
+	require.Equal(t, 7, list.Free)
+
+	res = serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/reservations/"+reservation.ReservationId+"/commit", nil)
+	require.Equal(t, http.StatusNotFound, res.Code)
+	require.Contains(t, res.Body.String(), "reservation-not-found")
+
+	res = serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/reservations", statusListReservationRequest{Ttl: 7200})
+	require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+}
+
+func TestExpiredReservation(t *testing.T) {
+	list := entity.NewListWithOptions(1, entity.ListOptions{Bits: 1, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	list.ListId = 1
+	env := newTestEnv(&fakeConnection{lists: map[int]*entity.List{1: list}, reservations: map[string]*entity.Reservation{}})
+
+	reservation, err := env.reserveStatusListEntry(context.Background(), "tenant", statusListReservationRequest{Ttl: 1})
+	require.NoError(t, err)
+	env.db.DbConnection.(*fakeConnection).reservations[reservation.ReservationId].ExpiresAt = time.Now().Add(-time.Second)
+
+	request, err := json.Marshal(ReservationRequest{Request: common.Request{TenantId: "tenant"}, ReservationId: reservation.ReservationId})
+	require.NoError(t, err)
+
+	rep := env.handleReservationEvent(context.Background(), "commit", request)
+	require.NotNil(t, rep.Error)
+	require.Equal(t, http.StatusGone, rep.Error.Status)
+	require.Contains(t, rep.Error.Msg, "reservation-expired")
+
+	require.NoError(t, releaseExpiredReservations(context.Background(), env.db, 10))
+	allocated, err := list.IsAllocated(reservation.Index)
+	require.NoError(t, err)
+	require.False(t, allocated)
+
+	rep = env.handleReservationEvent(context.Background(), "release", request)
+	require.NotNil(t, rep.Error)
+	require.Equal(t, http.StatusNotFound, rep.Error.Status)
+}
@@ -3,13 +3,18 @@ package api
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 
 	logger "github.com/sirupsen/logrus"
@@ -18,14 +23,27 @@ import (
 	server "github.com/eclipse-xfsc/microservice-core-go/pkg/server"
 	"github.com/eclipse-xfsc/statuslist-service/internal/config"
 	"github.com/eclipse-xfsc/statuslist-service/internal/database"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/eclipse-xfsc/statuslist-service/internal/signer"
 	"github.com/gin-gonic/gin"
 )
 
 type apienv struct {
-	db *database.Database
+	db          *database.Database
+	signer      signer.Signer
+	conf        *config.StatusListConfiguration
+	signedLists *signedListCache
+	// client fetches the status lists of other issuers
+	client *http.Client
+	// verifier checks the proofs of the fetched status lists
+	verifier signer.Verifier
 }
 
-var conf *config.StatusListConfiguration
+// List types which can be selected by X-TYPE or the default list type for credential output.
+const (
+	ListTypeStatusList2021 = "StatusList2021"
+	ListTypeBitstring      = "BitstringStatusList"
+)
 
 func (c *apienv) SetSwaggerBasePath(path string) {
 }
@@ -35,116 +53,233 @@ func (c *apienv) SwaggerOptions() []func(config *ginSwagger.Config) {
 	return make([]func(config *ginSwagger.Config), 0)
 }
 
-func handleGetList(ctx *gin.Context) {
+func (env *apienv) handleGetList(ctx *gin.Context) {
 	tenantId := ctx.Param("tenantId")
-	listId, err := strconv.Atoi(ctx.Param("listId"))
-
-	cty := ctx.ContentType()
+	listId, err := pathInt(ctx, "listId")
 
 	if err != nil {
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		abortWithProblem(ctx, err)
 		return
 	}
 
-	b, err := db.GetStatusList(ctx, tenantId, listId)
-	if err != nil {
-		ctx.AbortWithStatus(http.StatusBadRequest)
+	cty := negotiateListContentType(ctx.Request.Header)
+
+	if cty == "" {
+		abortWithProblem(ctx, fmt.Errorf("%w: %s", errNotAcceptable, ctx.GetHeader("Accept")))
 		return
 	}
-	var buf bytes.Buffer
-	zw := gzip.NewWriter(&buf)
-
-	_, err = zw.Write(b)
 
+	list, at, err := env.statusList(ctx, tenantId, listId)
 	if err != nil {
-		ctx.AbortWithStatus(http.StatusInternalServerError)
+		abortWithProblem(ctx, err)
 		return
 	}
+	historical := !at.IsZero()
 
-	if err := zw.Close(); err != nil {
-		ctx.AbortWithStatus(http.StatusInternalServerError)
+	ttl := env.conf.ListTtlForTenant(tenantId)
+
+	if cty == ContentTypeOctetStream {
+		writeCacheable(ctx, ContentTypeOctetStream, listETag(tenantId, listId, list.Version, cty), list.List, list.LastUpdate, ttl)
 		return
 	}
 
-	if cty == "application/json" || cty == "" {
+	if cty == ContentTypeJson {
+		etag := listETag(tenantId, listId, list.Version, cty)
+		if writeNotModified(ctx, etag, ttl) {
+			return
+		}
+
+		compressed, err := compressGzip(list.List)
 
-		ctx.JSON(http.StatusOK, gin.H{
+		if err != nil {
+			abortWithProblem(ctx, err)
+			return
+		}
+
+		res, err := json.Marshal(gin.H{
 			"tenantId": tenantId,
 			"listId":   listId,
-			"list":     base64.RawStdEncoding.EncodeToString(buf.Bytes()),
+			"bits":     list.Bits,
+			"version":  list.Version,
+			"list":     base64.RawStdEncoding.EncodeToString(compressed),
 		})
+
+		if err != nil {
+			abortWithProblem(ctx, err)
+			return
+		}
+
+		writeCacheable(ctx, gin.MIMEJSON+"; charset=utf-8", etag, res, list.LastUpdate, ttl)
 		return
 	}
 
-	if cty == "statuslist+jwt" || cty == "application/vc+ld+json" {
-		key := ctx.Request.Header.Get("X-KEY")
-		did := ctx.Request.Header.Get("X-DID")
-		namespace := ctx.Request.Header.Get("X-NAMESPACE")
-		group := ctx.Request.Header.Get("X-GROUP") //can be ""!
-		host := ctx.Request.Header.Get("X-HOST")
-		listtype := ctx.Request.Header.Get("X-TYPE")
-
-		if key == "" {
-			key = conf.DefaultKey
+	// the values end up in the signature and the key of the signed list cache, so headers are only taken when valid
+	var headerErr error
+	header := func(name string, fallback string, valid func(string) bool) string {
+		value := ctx.Request.Header.Get(name)
+		if value == "" {
+			return fallback
 		}
-		if did == "" {
-			did = conf.DefaultDid
+		if !valid(value) && headerErr == nil {
+			headerErr = fmt.Errorf("%w: header %s", errInvalidRequest, name)
 		}
+		return value
+	}
 
-		if namespace == "" {
-			namespace = conf.DefaultNamespace
-		}
+	key := header("X-KEY", env.conf.DefaultKey, validSigningName.MatchString)
+	did := header("X-DID", env.conf.DefaultDid, validDid.MatchString)
+	namespace := header("X-NAMESPACE", env.conf.DefaultNamespace, validSigningName.MatchString)
+	group := header("X-GROUP", env.conf.DefaultGroup, validSigningName.MatchString) //can be ""!
+	host := header("X-HOST", env.conf.DefaultHost, validHost)
 
-		if group == "" {
-			group = conf.DefaultGroup
-		}
+	if headerErr != nil {
+		abortWithProblem(ctx, headerErr)
+		return
+	}
 
-		if host == "" {
-			host = conf.DefaultHost
-		}
+	// unknown list types fail before anything is cached
+	listtype := ctx.Request.Header.Get("X-TYPE")
+	if listtype == "" {
+		listtype = env.conf.DefaultListType
+	}
 
-		if listtype == "" {
-			listtype = conf.DefaultListType
-		}
+	keyRef := signer.KeyRef{
+		TenantId:           tenantId,
+		Namespace:          namespace,
+		Group:              group,
+		Key:                key,
+		VerificationMethod: did + "#" + key,
+	}
 
-		if cty == "statuslist+jwt" {
+	cacheKey := signedListKey{
+		TenantId: tenantId,
+		ListId:   listId,
+		Format:   cty,
+		Key:      strings.Join([]string{namespace, group, key, did, host}, "|"),
+	}
 
-			res, err := requestTokenSigning(tenantId, base64.RawStdEncoding.EncodeToString(buf.Bytes()), key, namespace, group, did, host, 1, listId)
+	if cty == ContentTypeVcLdJson || cty == ContentTypeVcJwt {
+		cacheKey.Format = cty + "|" + listtype
+	}
 
-			if err != nil {
-				ctx.AbortWithStatus(http.StatusInternalServerError)
-				return
-			}
+	responseType := cty
+	if cty == ContentTypeVcLdJson {
+		responseType = gin.MIMEJSON + "; charset=utf-8"
+	}
 
-			ctx.JSON(http.StatusOK, string(res))
+	// the ETag does not depend on the signature, so clients holding this version are answered without signing
+	etag := signedListETag(tenantId, listId, list.Version, cacheKey.Format+"|"+cacheKey.Key)
+	if writeNotModified(ctx, etag, ttl) {
+		return
+	}
+
+	if !historical {
+		if cached, ok := env.signedLists.Get(cacheKey, list.List); ok {
+			writeCacheable(ctx, responseType, etag, cached.Data, latest(list.LastUpdate, cached.SignedAt), ttl)
 			return
 		}
+	}
+
+	res, err := env.signList(ctx, cty, listtype, keyRef, did, host, list, listId)
+
+	if err != nil {
+		logger.Error("Error signing status list", err.Error())
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	// past versions are rarely requested and would evict the current version from the cache
+	if historical {
+		writeCacheable(ctx, responseType, etag, res, list.LastUpdate, ttl)
+		return
+	}
+
+	signed := env.signedLists.Put(cacheKey, list.List, res, env.signedLists.refreshAt(env.conf, tenantId))
+
+	writeCacheable(ctx, responseType, etag, signed.Data, latest(list.LastUpdate, signed.SignedAt), ttl)
+}
+
+var validSigningName = regexp.MustCompile(`^[a-zA-Z0-9_./-]{1,128}$`)
+
+// validDid accepts the DID syntax of DID Core, see https://www.w3.org/TR/did-core/#did-syntax
+var validDid = regexp.MustCompile(`^did:[a-z0-9]+:[a-zA-Z0-9._:%-]{1,512}$`)
+
+// validHost accepts absolute http(s) URLs without query and fragment, entries of the list are appended to them.
+func validHost(host string) bool {
+	u, err := url.Parse(host)
+	return err == nil && len(host) <= 2048 && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.RawQuery == "" && u.Fragment == ""
+}
+
+// latest returns the later of both times. A list which was signed again is modified even if its status did not change.
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+var errUnknownListType = errors.New("unknown list type")
+
+// signList builds the list in the negotiated content type and lets the signer of the tenant secure it.
+func (env *apienv) signList(ctx context.Context, cty, listtype string, keyRef signer.KeyRef, did, host string, list *entity.List, listId int) ([]byte, error) {
+	if cty == ContentTypeStatusListJwt {
+		return env.requestTokenSigning(ctx, keyRef, did, host, list, listId)
+	}
+
+	if cty == ContentTypeStatusListCwt {
+		return env.requestCwtSigning(ctx, keyRef, did, host, list, listId)
+	}
+
+	var credential map[string]interface{}
+
+	if listtype == ListTypeStatusList2021 {
+		compressed, err := compressGzip(list.List)
+		if err != nil {
+			return nil, err
+		}
+		credential = buildCredential2021(base64.RawStdEncoding.EncodeToString(compressed), did, host, strconv.Itoa(listId), list.Purpose)
+	}
 
-		if cty == "application/vc+ld+json" {
-			if listtype == "StatusList2021" {
-				res, err := handleCredentialSigning2021(tenantId, base64.RawStdEncoding.EncodeToString(buf.Bytes()), key, namespace, group, did, host, strconv.Itoa(listId))
-				if err != nil {
-					ctx.AbortWithStatus(http.StatusInternalServerError)
-					return
-				}
-
-				ctx.JSON(http.StatusOK, res)
-				return
-			}
+	if listtype == ListTypeBitstring {
+		compressed, err := compressGzip(list.Bitstring())
+		if err != nil {
+			return nil, err
 		}
+		credential = buildCredentialBitstring(env.conf, keyRef.TenantId, "u"+base64.RawURLEncoding.EncodeToString(compressed), did, host, strconv.Itoa(listId), list)
 	}
 
-	ctx.AbortWithStatus(http.StatusBadRequest)
+	if credential == nil {
+		return nil, fmt.Errorf("%w: %s", errUnknownListType, listtype)
+	}
+
+	if cty == ContentTypeVcLdJson {
+		res, err := env.signer.AddProof(ctx, keyRef, credential)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(res)
+	}
+
+	return env.signer.SignJwt(ctx, keyRef, map[string]interface{}{"typ": "vc+jwt", "kid": keyRef.VerificationMethod}, credential)
 }
 
-func handleCredentialSigning2021(tenantId, statusList, key, namespace, group, did, host, listid string) (map[string]interface{}, error) {
+func compressGzip(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
 
-	payload := make(map[string]interface{})
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
 
-	payload["namespace"] = namespace
-	payload["group"] = group
-	payload["key"] = key
+	return buf.Bytes(), nil
+}
 
+func buildCredential2021(statusList, did, host, listid, purpose string) map[string]interface{} {
 	credential := make(map[string]interface{})
 	credential["@context"] = []string{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/vc/status-list/2021/v1", "https://w3id.org/security/suites/jws-2020/v1"}
 	credential["type"] = []string{"VerifiableCredential", "StatusList2021Credential"}
@@ -154,94 +289,494 @@ func handleCredentialSigning2021(tenantId, statusList, key, namespace, group, di
 	subject := make(map[string]interface{})
 	subject["id"] = host + "/" + listid + "#list"
 	subject["type"] = "StatusList2021"
-	subject["statusPurpose"] = "revocation"
+	subject["statusPurpose"] = purpose
 	subject["encodedList"] = statusList
 	credential["credentialSubject"] = subject
-	payload["credential"] = credential
-	p, err := json.Marshal(payload)
 
+	return credential
+}
+
+// buildCredentialBitstring builds a BitstringStatusListCredential as defined by https://www.w3.org/TR/vc-bitstring-status-list/. The statusList must already be the multibase encoded list.Bitstring().
+func buildCredentialBitstring(c *config.StatusListConfiguration, tenantId, statusList, did, host, listid string, list *entity.List) map[string]interface{} {
+	now := time.Now().UTC()
+
+	credential := make(map[string]interface{})
+	credential["@context"] = []string{"https://www.w3.org/ns/credentials/v2"}
+	credential["type"] = []string{"VerifiableCredential", "BitstringStatusListCredential"}
+	credential["id"] = host + "/" + listid
+	credential["issuer"] = did
+	credential["validFrom"] = now.Format(time.RFC3339)
+	credential["validUntil"] = now.Add(c.ListValidityForTenant(tenantId)).Format(time.RFC3339)
+	subject := make(map[string]interface{})
+	subject["id"] = host + "/" + listid + "#list"
+	subject["type"] = "BitstringStatusList"
+	subject["statusPurpose"] = bitstringPurpose(list.Purpose, list.Bits)
+	subject["encodedList"] = statusList
+	subject["ttl"] = c.ListTtlForTenant(tenantId).Milliseconds()
+	if list.Bits > 1 {
+		subject["statusSize"] = list.Bits
+		subject["statusMessage"] = bitstringStatusMessages(list.Bits)
+	}
+	credential["credentialSubject"] = subject
+
+	return credential
+}
+
+// bitstringPurpose is the status purpose of a Bitstring Status List, lists with more than one bit carry status messages instead.
+func bitstringPurpose(purpose string, bits int) string {
+	if bits > 1 {
+		return entity.PurposeMessage
+	}
+	return purpose
+}
+
+// bitstringStatusMessages describes every value of a status size, the Bitstring Status List requires one message per value for lists with more than one bit.
+func bitstringStatusMessages(bits int) []map[string]string {
+	messages := make([]map[string]string, 1<<bits)
+	for value := range messages {
+		messages[value] = map[string]string{
+			"status":  "0x" + strconv.FormatInt(int64(value), 16),
+			"message": statusMessage(uint8(value)),
+		}
+	}
+	return messages
+}
+
+// statusMessage names the status values of the Token Status List registry.
+func statusMessage(value uint8) string {
+	switch value {
+	case entity.StatusValid:
+		return "valid"
+	case entity.StatusInvalid:
+		return "invalid"
+	case entity.StatusSuspended:
+		return "suspended"
+	}
+	return "application specific"
+}
+
+// statusList reads the list of the tenant. With the query parameter time it returns the list as it was at that instant together with the instant, otherwise the current list and a zero time.
+func (env *apienv) statusList(ctx *gin.Context, tenantId string, listId int) (*entity.List, time.Time, error) {
+	at, err := queryTime(ctx, "time")
 	if err != nil {
-		return nil, err
+		return nil, at, err
+	}
+
+	if at.IsZero() {
+		list, err := env.db.GetStatusList(ctx, tenantId, listId)
+		return list, at, err
 	}
 
-	rep, err := http.DefaultClient.Post(conf.SignerUrl+"/credential/proof", "application/json", bytes.NewBuffer(p))
+	list, err := env.db.GetStatusListAt(ctx, tenantId, listId, at)
+	return list, at, err
+}
 
+func (env *apienv) handleGetEntry(ctx *gin.Context) {
+	tenantId := ctx.Param("tenantId")
+	listId, err := pathInt(ctx, "listId")
 	if err != nil {
-		return nil, err
+		abortWithProblem(ctx, err)
+		return
+	}
+	index, err := pathInt(ctx, "index")
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
 	}
 
-	respBody, err := io.ReadAll(rep.Body)
+	list, at, err := env.statusList(ctx, tenantId, listId)
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
 
+	entry, err := env.entryStatus(ctx, tenantId, listId, index, list, at)
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entry)
+}
+
+// entryStatus describes the status of the entry at index of the list. The list is the version at the given time, the current one for a zero time.
+func (env *apienv) entryStatus(ctx context.Context, tenantId string, listId int, index int, list *entity.List, at time.Time) (gin.H, error) {
+	status, err := list.StatusAtIndex(index)
 	if err != nil {
 		return nil, err
 	}
 
-	defer rep.Body.Close()
+	changedAt, err := env.db.GetLastStatusChange(ctx, tenantId, listId, index, at)
+	if err != nil {
+		return nil, err
+	}
 
-	if rep.StatusCode != http.StatusOK {
-		return nil, errors.New("signer service call error. result was: " + string(respBody))
+	var lastModified interface{}
+	if changedAt != nil {
+		lastModified = changedAt.UTC().Format(time.RFC3339)
 	}
 
-	var r map[string]interface{}
-	err = json.Unmarshal(respBody, &r)
+	// the index is in range, so the other lookups can not fail anymore
+	revoked, _ := list.IsRevoked(index)
+	suspended, _ := list.IsSuspended(index)
+
+	entry := gin.H{
+		"tenantId":     tenantId,
+		"listId":       listId,
+		"index":        index,
+		"status":       status,
+		"message":      statusMessage(status),
+		"revoked":      revoked,
+		"suspended":    suspended,
+		"purpose":      list.Purpose,
+		"bits":         list.Bits,
+		"lastModified": lastModified,
+	}
 
+	// the history keeps the status only, so past versions can not tell whether the index was allocated yet
+	if at.IsZero() {
+		entry["allocated"], _ = list.IsAllocated(index)
+	}
+
+	return entry, nil
+}
+
+// HeaderIdempotencyKey identifies repetitions of creation requests, they return the entries of the first request.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+func (env *apienv) handleCreateEntry(ctx *gin.Context) {
+	tenantId := ctx.Param("tenantId")
+
+	var request statusListEntryRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		abortWithProblem(ctx, fmt.Errorf("%w: %w", errInvalidRequest, err))
+		return
+	}
+	request.IdempotencyKey = ctx.GetHeader(HeaderIdempotencyKey)
+
+	entry, err := env.createStatusListEntry(ctx, tenantId, request)
 	if err != nil {
-		return nil, err
+		logger.Error("Error creating status list entry", err.Error())
+		abortWithProblem(ctx, err)
+		return
 	}
-	return r, nil
 
+	ctx.JSON(http.StatusCreated, gin.H{
+		"tenantId":         tenantId,
+		"listId":           entry.ListId,
+		"index":            entry.Index,
+		"statusUrl":        entry.StatusUrl,
+		"type":             entry.Type,
+		"purpose":          entry.Purpose,
+		"bits":             entry.Bits,
+		"credentialStatus": entry.credentialStatus(),
+	})
+}
+
+// statusListEntriesRequest asks for count entries with the same options, or one entry per credential reference.
+type statusListEntriesRequest struct {
+	statusListEntryRequest
+	Count          int      `json:"count"`
+	CredentialRefs []string `json:"credentialRefs,omitempty"`
 }
 
-func handleRevoke(ctx *gin.Context) {
+func (env *apienv) handleCreateEntries(ctx *gin.Context) {
 	tenantId := ctx.Param("tenantId")
-	listId, err := strconv.Atoi(ctx.Param("listId"))
+
+	var request statusListEntriesRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		abortWithProblem(ctx, fmt.Errorf("%w: %w", errInvalidRequest, err))
+		return
+	}
+	request.IdempotencyKey = ctx.GetHeader(HeaderIdempotencyKey)
+
+	entries, err := env.createStatusListEntries(ctx, tenantId, request.statusListEntryRequest, request.Count, request.CredentialRefs)
 	if err != nil {
-		logger.Error(err.Error(), "Error parsing listId")
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		logger.Error("Error creating status list entries", err.Error())
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"tenantId": tenantId,
+		"entries":  entries,
+	})
+}
+
+// HeaderActor names who changes the status of entries, e.g. the user which an authenticating proxy resolved.
+const HeaderActor = "X-ACTOR"
+
+// changeReasonRequest is the optional body of status changes.
+type changeReasonRequest struct {
+	Reason  string `json:"reason"`
+	Comment string `json:"comment"`
+}
+
+// changeReason completes the reason of the request with its actor and correlation id.
+func changeReason(ctx *gin.Context, request changeReasonRequest) (entity.ChangeReason, error) {
+	reason := entity.ChangeReason{
+		Actor:     ctx.GetHeader(HeaderActor),
+		Reason:    request.Reason,
+		Comment:   request.Comment,
+		RequestId: correlationId(ctx),
+	}
+
+	return reason, reason.Validate()
+}
+
+// revokeEntriesRequest lists the entries of a batch revocation. With Atomic set either all entries are revoked or none.
+type revokeEntriesRequest struct {
+	Entries []entity.Entry `json:"entries"`
+	Atomic  bool           `json:"atomic"`
+	changeReasonRequest
+}
+
+type revokeEntryResult struct {
+	entity.Entry
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (env *apienv) handleRevokeEntries(ctx *gin.Context) {
+	tenantId := ctx.Param("tenantId")
+
+	var request revokeEntriesRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		abortWithProblem(ctx, fmt.Errorf("%w: %w", errInvalidRequest, err))
+		return
+	}
+
+	if len(request.Entries) == 0 || len(request.Entries) > env.conf.MaxBatchSize {
+		abortWithProblem(ctx, fmt.Errorf("%w: %d not between 1 and %d", errInvalidCount, len(request.Entries), env.conf.MaxBatchSize))
 		return
 	}
-	index, err := strconv.Atoi(ctx.Param("index"))
+
+	reason, err := changeReason(ctx, request.changeReasonRequest)
 	if err != nil {
-		logger.Error(err.Error(), "Error parsing index")
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	// every entry is revoked once, repeated entries are reported as duplicates
+	positions := make(map[entity.Entry]int, len(request.Entries))
+	entries := make([]entity.Entry, 0, len(request.Entries))
+	for _, entry := range request.Entries {
+		if _, ok := positions[entry]; !ok {
+			positions[entry] = len(entries)
+			entries = append(entries, entry)
+		}
+	}
+
+	outcomes, err := env.db.RevokeCredentialsInSpecifiedLists(ctx, tenantId, entries, request.Atomic, reason)
+	if err != nil {
+		logger.Error("Error revoking credentials", err.Error())
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	results := make([]revokeEntryResult, len(request.Entries))
+	reported := make(map[entity.Entry]bool, len(entries))
+	changedLists := make(map[int]bool)
+	revoked, failed := 0, 0
+	for i, entry := range request.Entries {
+		results[i] = revokeEntryResult{Entry: entry, Status: "revoked"}
+
+		if reported[entry] {
+			results[i].Status = "duplicate"
+			continue
+		}
+		reported[entry] = true
+
+		if outcome := outcomes[positions[entry]]; outcome != nil {
+			results[i].Status = "failed"
+			results[i].Error = outcome.Error()
+			failed++
+			continue
+		}
+
+		revoked++
+		changedLists[entry.ListId] = true
+	}
+
+	for listId := range changedLists {
+		env.signedLists.Invalidate(tenantId, listId)
+	}
+
+	status := http.StatusOK
+	if request.Atomic && failed > 0 {
+		status = http.StatusConflict
+	}
+
+	ctx.JSON(status, gin.H{
+		"tenantId": tenantId,
+		"revoked":  revoked,
+		"failed":   failed,
+		"results":  results,
+	})
+}
+
+func (env *apienv) handleRevoke(ctx *gin.Context) {
+	env.handleStatusChange(ctx, env.db.RevokeCredentialInSpecifiedList, "revoked")
+}
+
+func (env *apienv) handleSuspend(ctx *gin.Context) {
+	env.handleStatusChange(ctx, env.db.SuspendCredentialInSpecifiedList, "suspended")
+}
+
+func (env *apienv) handleUnsuspend(ctx *gin.Context) {
+	env.handleStatusChange(ctx, env.db.UnsuspendCredentialInSpecifiedList, "valid")
+}
+
+// setStatusRequest sets the status value of an entry, e.g. an application specific value of a list with several bits.
+type setStatusRequest struct {
+	Status *int `json:"status"`
+	changeReasonRequest
+}
+
+func (env *apienv) handleSetStatus(ctx *gin.Context) {
+	tenantId := ctx.Param("tenantId")
+	listId, err := pathInt(ctx, "listId")
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+	index, err := pathInt(ctx, "index")
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	var request setStatusRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		abortWithProblem(ctx, fmt.Errorf("%w: %w", errInvalidRequest, err))
 		return
 	}
 
-	err = db.RevokeCredentialInSpecifiedList(ctx, tenantId, listId, index)
+	// the largest status size holds 8 bits, the list checks the value against its own status size
+	if request.Status == nil || *request.Status < 0 || *request.Status > math.MaxUint8 {
+		abortWithProblem(ctx, fmt.Errorf("%w: status must be between 0 and %d", entity.ErrInvalidStatus, math.MaxUint8))
+		return
+	}
+
+	reason, err := changeReason(ctx, request.changeReasonRequest)
 	if err != nil {
-		logger.Error("Error revoking credential", err.Error())
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		abortWithProblem(ctx, err)
 		return
 	}
 
+	if err := env.db.UpdateStatusInSpecifiedList(ctx, tenantId, listId, index, uint8(*request.Status), reason); err != nil {
+		logger.Error("Error setting credential status", err.Error())
+		abortWithProblem(ctx, err)
+		return
+	}
+	env.signedLists.Invalidate(tenantId, listId)
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"tenantId": tenantId,
 		"listId":   listId,
 		"index":    index,
-		"status":   "revoked",
+		"status":   *request.Status,
+		"reason":   reason.Reason,
 	})
 }
 
-func startRest(c *config.StatusListConfiguration, wg *sync.WaitGroup, db *database.Database) {
-	defer wg.Done()
-	conf = c
-	env := new(apienv)
-	env.SetDb(db)
+func (env *apienv) handleStatusChange(ctx *gin.Context, change statusChange, status string) {
+	tenantId := ctx.Param("tenantId")
+	listId, err := pathInt(ctx, "listId")
+	if err != nil {
+		logger.Error(err.Error(), "Error parsing listId")
+		abortWithProblem(ctx, err)
+		return
+	}
+	index, err := pathInt(ctx, "index")
+	if err != nil {
+		logger.Error(err.Error(), "Error parsing index")
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	response, err := env.changeEntryStatus(ctx, tenantId, entity.Entry{ListId: listId, Index: index}, change, status)
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// statusChange changes the status of an entry for a reason.
+type statusChange func(ctx context.Context, tenantId string, listId int, index int, reason entity.ChangeReason) error
+
+// changeEntryStatus applies change to the entry with the reason of the optional request body and describes the result.
+func (env *apienv) changeEntryStatus(ctx *gin.Context, tenantId string, entry entity.Entry, change statusChange, status string) (gin.H, error) {
+	var request changeReasonRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", errInvalidRequest, err)
+	}
+
+	reason, err := changeReason(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := change(ctx, tenantId, entry.ListId, entry.Index, reason); err != nil {
+		logger.Error("Error changing credential status", err.Error())
+		return nil, err
+	}
+	env.signedLists.Invalidate(tenantId, entry.ListId)
+
+	return gin.H{
+		"tenantId": tenantId,
+		"listId":   entry.ListId,
+		"index":    entry.Index,
+		"status":   status,
+		"reason":   reason.Reason,
+	}, nil
+}
+
+func startRest(env *apienv) {
 
 	srv := server.New(env)
 
-	srv.Add(func(tenantsGrp *gin.RouterGroup) {
-		grp := tenantsGrp.Group("/status")
-		grp.POST("/:listId/revoke/:index", handleRevoke)
-		grp.GET("/:listId", handleGetList)
-	})
+	srv.Add(env.addRoutes)
 
-	err := srv.Run(c.ListenPort)
+	err := srv.Run(env.conf.ListenPort)
 	if err != nil {
 		panic(err)
 	}
 }
 
+// addRoutes registers the handlers below /v1/tenants/:tenantId.
+func (env *apienv) addRoutes(tenantsGrp *gin.RouterGroup) {
+	grp := tenantsGrp.Group("/status")
+	grp.GET("", env.handleGetLists)
+	grp.POST("", env.handleCreateEntry)
+	grp.POST("/batch", env.handleCreateEntries)
+	grp.POST("/revoke", env.handleRevokeEntries)
+	grp.GET("/audit", env.handleGetStatusChanges)
+	grp.GET("/scheduled", env.handleGetScheduledChanges)
+	grp.POST("/reservations", env.handleReserveEntry)
+	grp.POST("/reservations/:reservationId/commit", env.handleCommitReservation)
+	grp.POST("/reservations/:reservationId/release", env.handleReleaseReservation)
+	grp.POST("/:listId/revoke/:index", env.handleRevoke)
+	grp.POST("/:listId/suspend/:index", env.handleSuspend)
+	grp.POST("/:listId/unsuspend/:index", env.handleUnsuspend)
+	grp.POST("/:listId/status/:index", env.handleSetStatus)
+	grp.GET("/:listId", env.handleGetList)
+	grp.GET("/:listId/:index", env.handleGetEntry)
+
+	// references may contain slashes, they are passed as query parameter ref then
+	credentials := tenantsGrp.Group("/credentials")
+	credentials.GET("", env.handleGetCredential)
+	credentials.POST("/revoke", env.handleCredentialRevoke)
+	credentials.POST("/suspend", env.handleCredentialSuspend)
+	credentials.POST("/unsuspend", env.handleCredentialUnsuspend)
+	credentials.GET("/:ref", env.handleGetCredential)
+	credentials.POST("/:ref/revoke", env.handleCredentialRevoke)
+	credentials.POST("/:ref/suspend", env.handleCredentialSuspend)
+	credentials.POST("/:ref/unsuspend", env.handleCredentialUnsuspend)
+}
+
 func (env *apienv) SetDb(db *database.Database) {
 	env.db = db
 }
@@ -0,0 +1,506 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/config"
+	"github.com/eclipse-xfsc/statuslist-service/internal/database"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConnection implements the database calls of the tests, all other calls panic.
+type fakeConnection struct {
+	database.DbConnection
+	revoke  func(entries []entity.Entry, atomic bool, reason entity.ChangeReason) ([]error, error)
+	lists   map[int]*entity.List
+	changes func(filter entity.StatusChangeFilter) []entity.StatusChange
+	// history holds the past versions of the lists by the instant they were replaced
+	history map[int]map[time.Time]*entity.List
+	due     func(limit int) []entity.ScheduledChange
+	// credentials registers the references of the entries
+	credentials map[string]*entity.CredentialEntry
+	// reservations holds the open reservations, they are allocated in the lists
+	reservations map[string]*entity.Reservation
+	// requests holds the allocations by idempotency key
+	requests map[string]fakeAllocation
+}
+
+type fakeAllocation struct {
+	fingerprint string
+	statusData  []*entity.StatusData
+}
+
+func (f *fakeConnection) GetStatusList(ctx context.Context, tenantId string, listId int) (*entity.List, error) {
+	list, ok := f.lists[listId]
+	if !ok {
+		return nil, entity.ErrListNotFound
+	}
+	return list, nil
+}
+
+func (f *fakeConnection) GetStatusListAt(ctx context.Context, tenantId string, listId int, at time.Time) (*entity.List, error) {
+	list, err := f.GetStatusList(ctx, tenantId, listId)
+	if err != nil || !list.LastUpdate.After(at) {
+		return list, err
+	}
+
+	var found *entity.List
+	for validUntil, version := range f.history[listId] {
+		if !version.LastUpdate.After(at) && validUntil.After(at) {
+			found = version
+		}
+	}
+	if found == nil {
+		return nil, entity.ErrListNotFound
+	}
+	return found, nil
+}
+
+func (f *fakeConnection) GetCredentialEntry(ctx context.Context, tenantId string, ref string) (*entity.CredentialEntry, error) {
+	entry, ok := f.credentials[ref]
+	if !ok {
+		return nil, entity.ErrCredentialNotFound
+	}
+	return entry, nil
+}
+
+func (f *fakeConnection) GetLastStatusChange(ctx context.Context, tenantId string, listId int, index int, at time.Time) (*time.Time, error) {
+	if f.changes == nil {
+		return nil, nil
+	}
+
+	var last *time.Time
+	for _, change := range f.changes(entity.StatusChangeFilter{ListId: &listId, Index: &index}) {
+		if (at.IsZero() || !change.Timestamp.After(at)) && (last == nil || change.Timestamp.After(*last)) {
+			last = &change.Timestamp
+		}
+	}
+	return last, nil
+}
+
+func (f *fakeConnection) RevokeCredentialInSpecifiedList(ctx context.Context, tenantId string, listId int, index int, reason entity.ChangeReason) error {
+	list, err := f.GetStatusList(ctx, tenantId, listId)
+	if err != nil {
+		return err
+	}
+	return list.RevokeAtIndex(index)
+}
+
+func (f *fakeConnection) UpdateStatusInSpecifiedList(ctx context.Context, tenantId string, listId int, index int, status uint8, reason entity.ChangeReason) error {
+	list, err := f.GetStatusList(ctx, tenantId, listId)
+	if err != nil {
+		return err
+	}
+	return list.SetStatusAtIndex(index, status)
+}
+
+func (f *fakeConnection) AllocateIndicesInCurrentList(ctx context.Context, tenantId string, options entity.ListOptions, count int, allocation entity.Allocation) ([]*entity.StatusData, error) {
+	key := allocation.Idempotency
+	if key != nil {
+		if earlier, ok := f.requests[key.Key]; ok {
+			if earlier.fingerprint != key.Fingerprint {
+				return nil, entity.ErrIdempotencyKeyReused
+			}
+			return earlier.statusData, nil
+		}
+	}
+
+	indices, err := f.lists[1].AllocateIndices(count)
+	if err != nil {
+		return nil, err
+	}
+
+	statusData := make([]*entity.StatusData, len(indices))
+	for i, index := range indices {
+		statusData[i] = entity.NewStatusData(index, 1)
+	}
+
+	if key != nil {
+		f.requests[key.Key] = fakeAllocation{fingerprint: key.Fingerprint, statusData: statusData}
+	}
+	return statusData, nil
+}
+
+func (f *fakeConnection) CacheList(ctx context.Context, cacheId string, list []byte) error {
+	return nil
+}
+
+func (f *fakeConnection) CreateTableForTenantIdIfNotExists(ctx context.Context, tenantId string) error {
+	return nil
+}
+
+func (f *fakeConnection) ReserveIndexInCurrentList(ctx context.Context, tenantId string, options entity.ListOptions, expiresAt time.Time) (*entity.Reservation, error) {
+	list, err := f.GetStatusList(ctx, tenantId, 1)
+	if err != nil {
+		return nil, err
+	}
+	index, err := list.AllocateIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	reservation := &entity.Reservation{Id: fmt.Sprintf("reservation-%d", index), TenantId: tenantId, ListId: 1, Index: index, ExpiresAt: expiresAt}
+	f.reservations[reservation.Id] = reservation
+	return reservation, nil
+}
+
+func (f *fakeConnection) CommitReservation(ctx context.Context, tenantId string, id string, now time.Time, allocation entity.Allocation) (*entity.Reservation, error) {
+	reservation, ok := f.reservations[id]
+	if !ok {
+		return nil, entity.ErrReservationNotFound
+	}
+	if reservation.Expired(now) {
+		return nil, entity.ErrReservationExpired
+	}
+
+	delete(f.reservations, id)
+	return reservation, nil
+}
+
+func (f *fakeConnection) ReleaseReservation(ctx context.Context, tenantId string, id string) (*entity.Reservation, error) {
+	reservation, ok := f.reservations[id]
+	if !ok {
+		return nil, entity.ErrReservationNotFound
+	}
+	if err := f.lists[reservation.ListId].ReleaseIndex(reservation.Index); err != nil {
+		return nil, err
+	}
+
+	delete(f.reservations, id)
+	return reservation, nil
+}
+
+func (f *fakeConnection) ReleaseExpiredReservations(ctx context.Context, now time.Time, limit int) ([]entity.Reservation, error) {
+	var released []entity.Reservation
+	for id, reservation := range f.reservations {
+		if len(released) == limit || !reservation.Expired(now) {
+			continue
+		}
+		// entries which can not be released stay allocated
+		_ = f.lists[reservation.ListId].ReleaseIndex(reservation.Index)
+		delete(f.reservations, id)
+		released = append(released, *reservation)
+	}
+	return released, nil
+}
+
+func (f *fakeConnection) ApplyDueStatusChanges(ctx context.Context, now time.Time, limit int) ([]entity.ScheduledChange, error) {
+	return f.due(limit), nil
+}
+
+func (f *fakeConnection) RevokeCredentialsInSpecifiedLists(ctx context.Context, tenantId string, entries []entity.Entry, atomic bool, reason entity.ChangeReason) ([]error, error) {
+	return f.revoke(entries, atomic, reason)
+}
+
+func (f *fakeConnection) GetStatusChanges(ctx context.Context, tenantId string, filter entity.StatusChangeFilter, offset int, limit int) ([]entity.StatusChange, int, error) {
+	changes := f.changes(filter)
+	return changes, len(changes), nil
+}
+
+func (f *fakeConnection) GetStatusLists(ctx context.Context, tenantId string, offset int, limit int) ([]entity.List, int, error) {
+	var lists []entity.List
+	for listId := 1; listId <= len(f.lists); listId++ {
+		lists = append(lists, *f.lists[listId])
+	}
+
+	total := len(lists)
+	if offset > total {
+		offset = total
+	}
+	lists = lists[offset:]
+	if len(lists) > limit {
+		lists = lists[:limit]
+	}
+	return lists, total, nil
+}
+
+// newTestEnv serves the handlers from fake with the defaults of the tests, which a test adjusts through env.conf.
+func newTestEnv(fake *fakeConnection) *apienv {
+	c := &config.StatusListConfiguration{
+		ListBits:          1,
+		AllocationMode:    entity.AllocationSequential,
+		ListPurpose:       entity.PurposeRevocation,
+		MaxBatchSize:      10,
+		DefaultListType:   ListTypeBitstring,
+		DefaultKey:        "key",
+		DefaultDid:        "did:web:issuer.example",
+		DefaultNamespace:  "transit",
+		DefaultHost:       "https://issuer.example/v1/tenants/tenant",
+		ReservationTtl:    time.Minute,
+		ReservationMaxTtl: time.Hour,
+		IdempotencyWindow: time.Hour,
+	}
+	return &apienv{db: &database.Database{DbConnection: fake}, conf: c, signedLists: newSignedListCache(10)}
+}
+
+// serveRequest routes the request to the handlers like the service.
+func serveRequest(env *apienv, request *http.Request) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	env.addRoutes(router.Group("/v1/tenants/:tenantId"))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// serveRest sends body as JSON, a nil body as empty request.
+func serveRest(env *apienv, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	return serveRequest(env, httptest.NewRequest(method, path, bytes.NewReader(payload)))
+}
+
+func TestRevokeEntriesReportsPartialFailure(t *testing.T) {
+	env := newTestEnv(&fakeConnection{revoke: func(entries []entity.Entry, atomic bool, reason entity.ChangeReason) ([]error, error) {
+		require.False(t, atomic)
+		require.Equal(t, entity.ReasonKeyCompromise, reason.Reason)
+		require.NotEmpty(t, reason.RequestId)
+		return []error{nil, entity.ErrAlreadyRevoked}, nil
+	}})
+
+	res := serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/revoke", revokeEntriesRequest{
+		Entries:             []entity.Entry{{ListId: 1, Index: 2}, {ListId: 2, Index: 3}},
+		changeReasonRequest: changeReasonRequest{Reason: entity.ReasonKeyCompromise},
+	})
+	require.Equal(t, http.StatusOK, res.Code)
+
+	var body struct {
+		Revoked int                 `json:"revoked"`
+		Failed  int                 `json:"failed"`
+		Results []revokeEntryResult `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	require.Equal(t, 1, body.Revoked)
+	require.Equal(t, 1, body.Failed)
+	require.Equal(t, "revoked", body.Results[0].Status)
+	require.Equal(t, "failed", body.Results[1].Status)
+	require.Equal(t, entity.ErrAlreadyRevoked.Error(), body.Results[1].Error)
+}
+
+func TestRevokeEntriesWithDuplicates(t *testing.T) {
+	env := newTestEnv(&fakeConnection{revoke: func(entries []entity.Entry, atomic bool, reason entity.ChangeReason) ([]error, error) {
+		require.Equal(t, []entity.Entry{{ListId: 1, Index: 2}, {ListId: 2, Index: 3}}, entries)
+		return []error{nil, entity.ErrAlreadyRevoked}, nil
+	}})
+
+	res := serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/revoke", revokeEntriesRequest{
+		Entries: []entity.Entry{{ListId: 1, Index: 2}, {ListId: 2, Index: 3}, {ListId: 1, Index: 2}, {ListId: 2, Index: 3}},
+	})
+	require.Equal(t, http.StatusOK, res.Code)
+
+	var body struct {
+		Revoked int                 `json:"revoked"`
+		Failed  int                 `json:"failed"`
+		Results []revokeEntryResult `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	require.Equal(t, 1, body.Revoked)
+	require.Equal(t, 1, body.Failed)
+	require.Equal(t, []string{"revoked", "failed", "duplicate", "duplicate"}, []string{body.Results[0].Status, body.Results[1].Status, body.Results[2].Status, body.Results[3].Status})
+	require.Empty(t, body.Results[2].Error)
+}
+
+func TestRevokeEntriesAtomicConflict(t *testing.T) {
+	env := newTestEnv(&fakeConnection{revoke: func(entries []entity.Entry, atomic bool, reason entity.ChangeReason) ([]error, error) {
+		require.True(t, atomic)
+		return []error{database.ErrNotApplied, entity.ErrAlreadyRevoked}, nil
+	}})
+
+	res := serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/revoke", revokeEntriesRequest{
+		Entries: []entity.Entry{{ListId: 1, Index: 2}, {ListId: 1, Index: 3}},
+		Atomic:  true,
+	})
+	require.Equal(t, http.StatusConflict, res.Code)
+
+	res = serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/revoke", revokeEntriesRequest{})
+	require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+
+	res = serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/revoke", revokeEntriesRequest{
+		Entries:             []entity.Entry{{ListId: 1, Index: 2}},
+		changeReasonRequest: changeReasonRequest{Reason: "bored"},
+	})
+	require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+}
+
+func TestGetEntry(t *testing.T) {
+	list := entity.NewListWithOptions(1, entity.ListOptions{Bits: 2, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	_, err := list.AllocateIndices(2)
+	require.NoError(t, err)
+	require.NoError(t, list.SuspendAtIndex(1))
+	list.LastUpdate = time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	suspended := entity.StatusChange{ListId: 1, Index: 1, NewStatus: entity.StatusSuspended, Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	env := newTestEnv(&fakeConnection{
+		lists: map[int]*entity.List{1: list},
+		changes: func(filter entity.StatusChangeFilter) []entity.StatusChange {
+			if *filter.ListId == suspended.ListId && *filter.Index == suspended.Index {
+				return []entity.StatusChange{suspended}
+			}
+			return nil
+		},
+	})
+
+	res := serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/1/1", nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.JSONEq(t, `{
+		"tenantId": "tenant",
+		"listId": 1,
+		"index": 1,
+		"status": 2,
+		"message": "suspended",
+		"revoked": false,
+		"suspended": true,
+		"allocated": true,
+		"purpose": "revocation",
+		"bits": 2,
+		"lastModified": "2024-01-02T03:04:05Z"
+	}`, res.Body.String())
+
+	// the status of the other entries never changed
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/1/0", nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Contains(t, res.Body.String(), `"lastModified":null`)
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/1/3", nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Contains(t, res.Body.String(), `"allocated":false`)
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/1/4", nil)
+	require.Equal(t, http.StatusNotFound, res.Code)
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/2/0", nil)
+	require.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestGetEntryAtTime(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	revoked := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	past := entity.NewList(1, 1)
+	_, err := past.AllocateIndices(4)
+	require.NoError(t, err)
+	past.LastUpdate = created
+
+	current := entity.NewList(1, 1)
+	_, err = current.AllocateIndices(4)
+	require.NoError(t, err)
+	require.NoError(t, current.RevokeAtIndex(2))
+	current.LastUpdate = revoked
+
+	env := newTestEnv(&fakeConnection{
+		lists:   map[int]*entity.List{1: current},
+		history: map[int]map[time.Time]*entity.List{1: {revoked: past}},
+		changes: func(filter entity.StatusChangeFilter) []entity.StatusChange {
+			return []entity.StatusChange{{ListId: 1, Index: 2, NewStatus: entity.StatusInvalid, Timestamp: revoked}}
+		},
+	})
+
+	res := serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/1/2?time=2024-02-01T00:00:00Z", nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Contains(t, res.Body.String(), `"revoked":false`)
+	require.Contains(t, res.Body.String(), `"lastModified":null`)
+	require.NotContains(t, res.Body.String(), `"allocated"`)
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/1/2?time=2024-04-01T00:00:00Z", nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Contains(t, res.Body.String(), `"revoked":true`)
+	require.Contains(t, res.Body.String(), `"lastModified":"2024-03-01T00:00:00Z"`)
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/1/2?time=2023-01-01T00:00:00Z", nil)
+	require.Equal(t, http.StatusNotFound, res.Code)
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/1/2?time=yesterday", nil)
+	require.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+func TestGetListsWithSummary(t *testing.T) {
+	first := entity.NewList(1, 1)
+	first.ListId = 1
+	_, err := first.AllocateIndices(8)
+	require.NoError(t, err)
+	require.NoError(t, first.RevokeAtIndex(3))
+
+	second := entity.NewListWithOptions(1, entity.ListOptions{Bits: 2, AllocationMode: entity.AllocationRandom, Purpose: entity.PurposeRevocation})
+	second.ListId = 2
+	_, err = second.AllocateIndices(2)
+	require.NoError(t, err)
+
+	env := newTestEnv(&fakeConnection{lists: map[int]*entity.List{1: first, 2: second}})
+
+	res := serveRest(env, http.MethodGet, "/v1/tenants/tenant/status?offset=1&limit=1", nil)
+	require.Equal(t, http.StatusOK, res.Code)
+
+	var body struct {
+		Total   int                 `json:"total"`
+		Lists   []listInventoryItem `json:"lists"`
+		Summary inventorySummary    `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	require.Equal(t, 2, body.Total)
+	require.Len(t, body.Lists, 1)
+	require.Equal(t, 2, body.Lists[0].ListId)
+	require.Equal(t, entity.ListStatistics{Size: 4, Allocated: 2, Free: 2}, body.Lists[0].ListStatistics)
+	require.Equal(t, entity.AllocationRandom, body.Lists[0].AllocationMode)
+
+	require.Equal(t, 2, body.Summary.Lists)
+	require.Equal(t, entity.ListStatistics{Size: 12, Allocated: 10, Free: 2, Revoked: 1}, body.Summary.ListStatistics)
+	require.InDelta(t, 10.0/12.0, body.Summary.Utilization, 0.0001)
+
+	res = serveRest(env, http.MethodGet, "/v1/tenants/tenant/status?limit=0", nil)
+	require.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+func TestGetListRejectsInvalidSigningHeaders(t *testing.T) {
+	list := entity.NewListWithOptions(1, entity.ListOptions{Bits: 1, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	env := newTestEnv(&fakeConnection{lists: map[int]*entity.List{1: list}})
+
+	for name, value := range map[string]string{
+		"X-KEY":       "key|other",
+		"X-DID":       "example.com",
+		"X-NAMESPACE": "transit namespace",
+		"X-HOST":      "ftp://issuer.example",
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/v1/tenants/tenant/status/1", nil)
+		request.Header.Set("Accept", ContentTypeStatusListJwt)
+		request.Header.Set(name, value)
+		require.Equal(t, http.StatusBadRequest, serveRequest(env, request).Code, name)
+	}
+	require.Empty(t, env.signedLists.entries)
+}
+
+func TestSetStatus(t *testing.T) {
+	list := entity.NewListWithOptions(2, entity.ListOptions{Bits: 4, AllocationMode: entity.AllocationSequential, Purpose: entity.PurposeRevocation})
+	_, err := list.AllocateIndices(2)
+	require.NoError(t, err)
+	env := newTestEnv(&fakeConnection{lists: map[int]*entity.List{1: list}})
+
+	res := serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/1/status/1", gin.H{"status": 9})
+	require.Equal(t, http.StatusOK, res.Code)
+	status, err := list.StatusAtIndex(1)
+	require.NoError(t, err)
+	require.Equal(t, uint8(9), status)
+
+	for _, body := range []gin.H{{}, {"status": -1}, {"status": 256}, {"status": 16}} {
+		res = serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/1/status/1", body)
+		require.Equal(t, http.StatusUnprocessableEntity, res.Code, body)
+	}
+
+	res = serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/1/status/3", gin.H{"status": 1})
+	require.Equal(t, http.StatusConflict, res.Code)
+
+	require.NoError(t, list.RevokeAtIndex(0))
+	res = serveRest(env, http.MethodPost, "/v1/tenants/tenant/status/1/status/0", gin.H{"status": 0})
+	require.Equal(t, http.StatusConflict, res.Code)
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/config"
+	"github.com/eclipse-xfsc/statuslist-service/internal/database"
+	"github.com/gin-gonic/gin"
+	logger "github.com/sirupsen/logrus"
+)
+
+// startScheduler applies the due scheduled status changes, releases the expired reservations and forgets the expired idempotency keys every interval until ctx is done. Every replica runs a scheduler, the database hands out each change to one of them.
+func startScheduler(ctx context.Context, c *config.StatusListConfiguration, wg *sync.WaitGroup, database *database.Database, signedLists *signedListCache) {
+	defer wg.Done()
+
+	if c.SchedulerInterval <= 0 {
+		logger.Info("Scheduler is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(c.SchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Scheduler stopped")
+			return
+		case <-ticker.C:
+			runScheduler(ctx, c, database, signedLists)
+		}
+	}
+}
+
+// runScheduler runs one pass of the scheduler.
+func runScheduler(ctx context.Context, c *config.StatusListConfiguration, database *database.Database, signedLists *signedListCache) {
+	if err := applyDueStatusChanges(ctx, database, signedLists, c.SchedulerBatch); err != nil {
+		logger.Error("Error applying scheduled status changes", err.Error())
+	}
+
+	if err := releaseExpiredReservations(ctx, database, c.SchedulerBatch); err != nil {
+		logger.Error("Error releasing expired reservations", err.Error())
+	}
+
+	if c.IdempotencyWindow > 0 {
+		if _, err := database.DeleteIdempotencyKeys(ctx, time.Now().Add(-c.IdempotencyWindow)); err != nil {
+			logger.Error("Error deleting expired idempotency keys", err.Error())
+		}
+	}
+}
+
+// applyDueStatusChanges applies the due changes in batches of limit until none is left.
+func applyDueStatusChanges(ctx context.Context, database *database.Database, signedLists *signedListCache, limit int) error {
+	for {
+		changes, err := database.ApplyDueStatusChanges(ctx, time.Now(), limit)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			if change.Error != "" {
+				logger.Warnf("Scheduled %s of entry %d in list %d of tenant %s failed: %s", change.Action, change.Index, change.ListId, change.TenantId, change.Error)
+				continue
+			}
+			signedLists.Invalidate(change.TenantId, change.ListId)
+		}
+
+		if len(changes) < limit {
+			return nil
+		}
+	}
+}
+
+func (env *apienv) handleGetScheduledChanges(ctx *gin.Context) {
+	tenantId := ctx.Param("tenantId")
+
+	// Scheduled changes have no actor yet, the scheduler applies them.
+	if ctx.Query("actor") != "" {
+		abortWithProblem(ctx, fmt.Errorf("%w: scheduled changes can not be filtered by actor", errInvalidRequest))
+		return
+	}
+
+	filter, err := statusChangeFilter(ctx)
+	if err != nil {
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	offset, limit, ok := pageParams(ctx)
+	if !ok {
+		return
+	}
+
+	changes, total, err := env.db.GetScheduledChanges(ctx, tenantId, filter, offset, limit)
+	if err != nil {
+		logger.Error("Error reading scheduled status changes", err.Error())
+		abortWithProblem(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"tenantId": tenantId,
+		"offset":   offset,
+		"limit":    limit,
+		"total":    total,
+		"changes":  changes,
+	})
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/config"
+	"github.com/eclipse-xfsc/statuslist-service/internal/database"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDueStatusChanges(t *testing.T) {
+	batches := [][]entity.ScheduledChange{
+		{
+			{TenantId: "tenant", ListId: 1, Index: 1, Action: entity.ActionRevoke},
+			{TenantId: "tenant", ListId: 2, Index: 1, Action: entity.ActionRevoke, Error: entity.ErrAlreadyRevoked.Error()},
+		},
+		{
+			{TenantId: "tenant", ListId: 3, Index: 5, Action: entity.ActionSuspend},
+		},
+	}
+	calls := 0
+	fake := &fakeConnection{due: func(limit int) []entity.ScheduledChange {
+		require.Equal(t, 2, limit)
+		calls++
+		if len(batches) == 0 {
+			return nil
+		}
+		batch := batches[0]
+		batches = batches[1:]
+		return batch
+	}}
+
+	signedLists := newSignedListCache(10)
+	refreshAt := time.Now().Add(time.Hour)
+	for listId := 1; listId <= 3; listId++ {
+		signedLists.Put(signedListKey{TenantId: "tenant", ListId: listId}, []byte{0}, []byte("signed"), refreshAt)
+	}
+
+	require.NoError(t, applyDueStatusChanges(context.Background(), &database.Database{DbConnection: fake}, signedLists, 2))
+	require.Equal(t, 2, calls)
+
+	_, ok := signedLists.Get(signedListKey{TenantId: "tenant", ListId: 1}, []byte{0})
+	require.False(t, ok)
+	_, ok = signedLists.Get(signedListKey{TenantId: "tenant", ListId: 2}, []byte{0})
+	require.True(t, ok)
+	_, ok = signedLists.Get(signedListKey{TenantId: "tenant", ListId: 3}, []byte{0})
+	require.False(t, ok)
+}
+
+func TestStartSchedulerStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	passes := make(chan struct{}, 1)
+	fake := &fakeConnection{due: func(limit int) []entity.ScheduledChange {
+		select {
+		case passes <- struct{}{}:
+		default:
+		}
+		return nil
+	}}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go startScheduler(ctx, &config.StatusListConfiguration{SchedulerInterval: time.Millisecond, SchedulerBatch: 10}, &wg, &database.Database{DbConnection: fake}, newSignedListCache(10))
+
+	<-passes
+	cancel()
+	wg.Wait()
+}
+
+func TestGetScheduledChangesRejectsActor(t *testing.T) {
+	env := newTestEnv(&fakeConnection{})
+
+	res := serveRest(env, http.MethodGet, "/v1/tenants/tenant/status/scheduled?actor=alice", nil)
+	require.Equal(t, http.StatusBadRequest, res.Code)
+}
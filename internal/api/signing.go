@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/eclipse-xfsc/statuslist-service/internal/signer"
+)
+
+func (env *apienv) requestTokenSigning(ctx context.Context, key signer.KeyRef, did, host string, list *entity.List, listId int) ([]byte, error) {
+	token, err := newStatusListToken(env.conf, key.TenantId, did, host, list, listId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return env.signer.SignJwt(ctx, key, token.jwtHeader(key.VerificationMethod), token.jwtClaims())
+}
+
+func (env *apienv) requestCwtSigning(ctx context.Context, key signer.KeyRef, did, host string, list *entity.List, listId int) ([]byte, error) {
+	token, err := newStatusListToken(env.conf, key.TenantId, did, host, list, listId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := token.cwtClaims()
+
+	if err != nil {
+		return nil, err
+	}
+
+	header := map[int]interface{}{
+		coseHeaderKid: []byte(key.VerificationMethod),
+		coseHeaderTyp: ContentTypeStatusListCwt,
+	}
+
+	return env.signer.SignCwt(ctx, key, header, payload)
+}
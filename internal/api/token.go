@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/config"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// statusListToken holds the claims of a Token Status List which are shared by the JWT and the CWT form, see https://datatracker.ietf.org/doc/draft-ietf-oauth-status-list/
+type statusListToken struct {
+	Issuer   string
+	Subject  string
+	IssuedAt time.Time
+	Expires  time.Time
+	Ttl      time.Duration
+	Bits     int
+	// List is compressed with ZLIB
+	List []byte
+}
+
+func newStatusListToken(c *config.StatusListConfiguration, tenantId, did, host string, list *entity.List, listId int) (*statusListToken, error) {
+	lst, err := compressZlib(list.List)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	return &statusListToken{
+		Issuer:   did,
+		Subject:  statusListUri(host, listId),
+		IssuedAt: now,
+		Expires:  now.Add(c.ListValidityForTenant(tenantId)),
+		Ttl:      c.ListTtlForTenant(tenantId),
+		Bits:     list.Bits,
+		List:     lst,
+	}, nil
+}
+
+// statusListUri returns the uri under which the list is served. The sub claim must match the uri which referenced tokens point to.
+func statusListUri(host string, listId int) string {
+	return host + "/status/" + strconv.Itoa(listId)
+}
+
+func (t *statusListToken) jwtClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss": t.Issuer,
+		"sub": t.Subject,
+		"iat": t.IssuedAt.Unix(),
+		"exp": t.Expires.Unix(),
+		"ttl": int64(t.Ttl.Seconds()),
+		"status_list": map[string]interface{}{
+			"bits": t.Bits,
+			"lst":  base64.RawURLEncoding.EncodeToString(t.List),
+		},
+	}
+}
+
+func (t *statusListToken) jwtHeader(kid string) map[string]interface{} {
+	return map[string]interface{}{
+		"typ": "statuslist+jwt",
+		"kid": kid,
+	}
+}
+
+// cwtClaims encodes the claims in deterministic CBOR.
+func (t *statusListToken) cwtClaims() ([]byte, error) {
+	claims := map[int]interface{}{
+		cwtClaimIss: t.Issuer,
+		cwtClaimSub: t.Subject,
+		cwtClaimIat: t.IssuedAt.Unix(),
+		cwtClaimExp: t.Expires.Unix(),
+		cwtClaimTtl: int64(t.Ttl.Seconds()),
+		cwtClaimStatusList: map[string]interface{}{
+			"bits": t.Bits,
+			"lst":  t.List,
+		},
+	}
+
+	encoder, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.Marshal(claims)
+}
+
+// compressZlib compresses the list with ZLIB as required by the Token Status List.
+func compressZlib(list []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+
+	if _, err := zw.Write(list); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
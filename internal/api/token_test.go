@@ -0,0 +1,81 @@
+package api
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/config"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusListTokenClaims(t *testing.T) {
+	c := &config.StatusListConfiguration{
+		ListValidity: time.Hour,
+		ListTtl:      5 * time.Minute,
+	}
+
+	list := entity.NewList(4, 2)
+	_, err := list.AllocateIndices(4)
+	require.NoError(t, err)
+	require.NoError(t, list.SuspendAtIndex(3))
+
+	token, err := newStatusListToken(c, "tenant", "did:web:example.com", "https://example.com", list, 7)
+	require.NoError(t, err)
+
+	claims := token.jwtClaims()
+	require.Equal(t, "did:web:example.com", claims["iss"])
+	require.Equal(t, "https://example.com/status/7", claims["sub"])
+	require.Equal(t, int64(300), claims["ttl"])
+	require.Equal(t, claims["iat"].(int64)+3600, claims["exp"])
+	require.Equal(t, "statuslist+jwt", token.jwtHeader("kid")["typ"])
+
+	statusList := claims["status_list"].(map[string]interface{})
+	require.Equal(t, 2, statusList["bits"])
+
+	compressed, err := base64.RawURLEncoding.DecodeString(statusList["lst"].(string))
+	require.NoError(t, err)
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	require.Equal(t, list.List, decompressed)
+}
+
+func TestStatusListTokenValidityPerTenant(t *testing.T) {
+	c := &config.StatusListConfiguration{
+		ListValidity:   time.Hour,
+		TenantValidity: map[string]time.Duration{"other": time.Minute},
+	}
+
+	token, err := newStatusListToken(c, "other", "did:web:example.com", "https://example.com", entity.NewList(1, 1), 1)
+	require.NoError(t, err)
+
+	require.Equal(t, time.Minute, token.Expires.Sub(token.IssuedAt))
+}
+
+func TestStatusListTokenCwtClaims(t *testing.T) {
+	c := &config.StatusListConfiguration{
+		ListValidity: time.Hour,
+		ListTtl:      time.Minute,
+	}
+
+	token, err := newStatusListToken(c, "tenant", "did:web:example.com", "https://example.com", entity.NewList(1, 1), 1)
+	require.NoError(t, err)
+
+	encoded, err := token.cwtClaims()
+	require.NoError(t, err)
+
+	var claims map[int]interface{}
+	require.NoError(t, cbor.Unmarshal(encoded, &claims))
+	require.Equal(t, "https://example.com/status/1", claims[cwtClaimSub])
+	require.Equal(t, uint64(60), claims[cwtClaimTtl])
+
+	statusList := claims[cwtClaimStatusList].(map[interface{}]interface{})
+	require.Equal(t, token.List, statusList["lst"])
+}
@@ -2,31 +2,55 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
 
 	cloudeventprovider "github.com/eclipse-xfsc/cloud-event-provider"
 	"github.com/eclipse-xfsc/microservice-core-go/pkg/config"
 	pgPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/db/postgres"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
 	"github.com/kelseyhightower/envconfig"
 )
 
 var logger logr.Logger
 
 type StatusListConfiguration struct {
-	config.BaseConfig `mapstructure:",squash"`
-	Database          pgPkg.Config                  `mapstructure:"database" envconfig:"DATABASE"`
-	CreationTopic     string                        `mapstructure:"creationTopic" envconfig:"CREATIONTOPIC" default:"status.data.create"`
-	ListSizeInBytes   int                           `mapstructure:"listSizeInBytes" envconfig:"LISTSIZEINBYTES" default:"1024"`
-	Nats              cloudeventprovider.NatsConfig `envconfig:"NATS"`
-	SignerTopic       string                        `envconfig:"SIGNER_TOPIC" default:"signer"`
-	SignerUrl         string                        `envconfig:"SIGNER_URL" default:"signer"`
-	DefaultKey        string                        `envconfig:"DEFAULT_KEY" default:"test"`
-	DefaultDid        string                        `envconfig:"DEFAULT_DID" default:"did:web:localhost:8081:v1:did:document"`
-	DefaultNamespace  string                        `envconfig:"DEFAULT_NAMESPACE" default:"transit"`
-	DefaultGroup      string                        `envconfig:"DEFAULT_GROUP" default:""`
-	DefaultHost       string                        `envconfig:"DEFAULT_HOST" default:"http://localhost:8081/v1/tenants/transit"`
-	DefaultListType   string                        `envconfig:"DEFAULT_LISTTYPE" default:"StatusList2021"`
+	config.BaseConfig   `mapstructure:",squash"`
+	Database            pgPkg.Config                  `mapstructure:"database" envconfig:"DATABASE"`
+	CreationTopic       string                        `mapstructure:"creationTopic" envconfig:"CREATIONTOPIC" default:"status.data.create"`
+	ListSizeInBytes     int                           `mapstructure:"listSizeInBytes" envconfig:"LISTSIZEINBYTES" default:"1024"`
+	ListBits            int                           `mapstructure:"listBits" envconfig:"LISTBITS" default:"1"`
+	ListPurpose         string                        `mapstructure:"listPurpose" envconfig:"LISTPURPOSE" default:"revocation"`
+	AllocationMode      string                        `mapstructure:"allocationMode" envconfig:"ALLOCATIONMODE" default:"sequential"`
+	TenantAllocation    map[string]string             `mapstructure:"tenantAllocation" envconfig:"TENANT_ALLOCATION"`
+	MaxBatchSize        int                           `mapstructure:"maxBatchSize" envconfig:"MAXBATCHSIZE" default:"100000"`
+	SchedulerInterval   time.Duration                 `mapstructure:"schedulerInterval" envconfig:"SCHEDULER_INTERVAL" default:"1m"`
+	SchedulerBatch      int                           `mapstructure:"schedulerBatch" envconfig:"SCHEDULER_BATCHSIZE" default:"1000"`
+	ReservationTtl      time.Duration                 `mapstructure:"reservationTtl" envconfig:"RESERVATION_TTL" default:"5m"`
+	ReservationMaxTtl   time.Duration                 `mapstructure:"reservationMaxTtl" envconfig:"RESERVATION_MAXTTL" default:"1h"`
+	IdempotencyWindow   time.Duration                 `mapstructure:"idempotencyWindow" envconfig:"IDEMPOTENCY_WINDOW" default:"24h"`
+	Nats                cloudeventprovider.NatsConfig `envconfig:"NATS"`
+	SignerTopic         string                        `envconfig:"SIGNER_TOPIC" default:"signer"`
+	SignerUrl           string                        `envconfig:"SIGNER_URL" default:"signer"`
+	SignerType          string                        `envconfig:"SIGNER_TYPE" default:"remote"`
+	SignerKeyDir        string                        `envconfig:"SIGNER_KEYDIR" default:"keys"`
+	TenantSigner        map[string]string             `mapstructure:"tenantSigner" envconfig:"TENANT_SIGNER"`
+	SignerTimeout       time.Duration                 `envconfig:"SIGNER_TIMEOUT" default:"10s"`
+	SignerRetries       int                           `envconfig:"SIGNER_RETRIES" default:"2"`
+	SignerRetryDelay    time.Duration                 `envconfig:"SIGNER_RETRYDELAY" default:"500ms"`
+	FetchTimeout        time.Duration                 `envconfig:"FETCH_TIMEOUT" default:"10s"`
+	DefaultKey          string                        `envconfig:"DEFAULT_KEY" default:"test"`
+	DefaultDid          string                        `envconfig:"DEFAULT_DID" default:"did:web:localhost:8081:v1:did:document"`
+	DefaultNamespace    string                        `envconfig:"DEFAULT_NAMESPACE" default:"transit"`
+	DefaultGroup        string                        `envconfig:"DEFAULT_GROUP" default:""`
+	DefaultHost         string                        `envconfig:"DEFAULT_HOST" default:"http://localhost:8081/v1/tenants/transit"`
+	DefaultListType     string                        `envconfig:"DEFAULT_LISTTYPE" default:"StatusList2021"`
+	ListValidity        time.Duration                 `mapstructure:"listValidity" envconfig:"LISTVALIDITY" default:"24h"`
+	ListTtl             time.Duration                 `mapstructure:"listTtl" envconfig:"LISTTTL" default:"5m"`
+	TenantValidity      map[string]time.Duration      `mapstructure:"tenantValidity" envconfig:"TENANT_LISTVALIDITY"`
+	TenantTtl           map[string]time.Duration      `mapstructure:"tenantTtl" envconfig:"TENANT_LISTTTL"`
+	SignedListCacheSize int                           `mapstructure:"signedListCacheSize" envconfig:"SIGNEDLIST_CACHESIZE" default:"10000"`
 }
 
 var CurrentStatusListConfig StatusListConfiguration
@@ -40,6 +64,50 @@ func Load() error {
 	return err
 }
 
+// Validate checks the list defaults and the allocation modes of the tenants, so that a wrong configuration fails at startup instead of at the first allocation of a tenant.
+func (c *StatusListConfiguration) Validate() error {
+	defaults := entity.ListOptions{
+		Bits:           c.ListBits,
+		AllocationMode: c.AllocationMode,
+		Purpose:        c.ListPurpose,
+	}
+	if err := defaults.Validate(); err != nil {
+		return fmt.Errorf("invalid list defaults: %w", err)
+	}
+
+	for tenantId, mode := range c.TenantAllocation {
+		if !entity.ValidAllocationMode(mode) {
+			return fmt.Errorf("invalid allocation mode of tenant %s: %w: %s", tenantId, entity.ErrInvalidAllocationMode, mode)
+		}
+	}
+
+	return nil
+}
+
+// AllocationModeForTenant returns the allocation mode configured for the tenant or the default mode.
+func (c *StatusListConfiguration) AllocationModeForTenant(tenantId string) string {
+	if mode, ok := c.TenantAllocation[tenantId]; ok {
+		return mode
+	}
+	return c.AllocationMode
+}
+
+// ListValidityForTenant returns how long signed lists of the tenant are valid.
+func (c *StatusListConfiguration) ListValidityForTenant(tenantId string) time.Duration {
+	if validity, ok := c.TenantValidity[tenantId]; ok {
+		return validity
+	}
+	return c.ListValidity
+}
+
+// ListTtlForTenant returns how long verifiers may cache lists of the tenant.
+func (c *StatusListConfiguration) ListTtlForTenant(tenantId string) time.Duration {
+	if ttl, ok := c.TenantTtl[tenantId]; ok {
+		return ttl
+	}
+	return c.ListTtl
+}
+
 func SetLogger(log logr.Logger) {
 	logger = log
 }
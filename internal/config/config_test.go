@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	c := StatusListConfiguration{
+		ListBits:         1,
+		ListPurpose:      entity.PurposeRevocation,
+		AllocationMode:   entity.AllocationSequential,
+		TenantAllocation: map[string]string{"tenant": entity.AllocationRandom},
+	}
+	require.NoError(t, c.Validate())
+
+	c.TenantAllocation["other"] = "randm"
+	require.ErrorIs(t, c.Validate(), entity.ErrInvalidAllocationMode)
+
+	delete(c.TenantAllocation, "other")
+	c.ListBits = 3
+	require.ErrorIs(t, c.Validate(), entity.ErrInvalidBits)
+}
@@ -2,21 +2,44 @@ package database
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	pgPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/db/postgres"
 	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
 )
 
 type DbConnection interface {
-	AllocateIndexInCurrentList(ctx context.Context, tenantId string) (*entity.StatusData, error)
-	RevokeCredentialInSpecifiedList(ctx context.Context, tenantId string, listId int, index int) error
+	AllocateIndexInCurrentList(ctx context.Context, tenantId string, options entity.ListOptions) (*entity.StatusData, error)
+	AllocateIndicesInCurrentList(ctx context.Context, tenantId string, options entity.ListOptions, count int, allocation entity.Allocation) ([]*entity.StatusData, error)
+	RevokeCredentialInSpecifiedList(ctx context.Context, tenantId string, listId int, index int, reason entity.ChangeReason) error
+	SuspendCredentialInSpecifiedList(ctx context.Context, tenantId string, listId int, index int, reason entity.ChangeReason) error
+	UnsuspendCredentialInSpecifiedList(ctx context.Context, tenantId string, listId int, index int, reason entity.ChangeReason) error
+	RevokeCredentialsInSpecifiedLists(ctx context.Context, tenantId string, entries []entity.Entry, atomic bool, reason entity.ChangeReason) ([]error, error)
+	UpdateStatusInSpecifiedList(ctx context.Context, tenantId string, listId int, index int, status uint8, reason entity.ChangeReason) error
 	CreateTableForTenantIdIfNotExists(ctx context.Context, tenantId string) error
-	GetStatusList(ctx context.Context, tenantId string, listId int) ([]byte, error)
+	GetStatusList(ctx context.Context, tenantId string, listId int) (*entity.List, error)
+	GetStatusListAt(ctx context.Context, tenantId string, listId int, at time.Time) (*entity.List, error)
+	GetStatusLists(ctx context.Context, tenantId string, offset int, limit int) ([]entity.List, int, error)
+	GetStatusChanges(ctx context.Context, tenantId string, filter entity.StatusChangeFilter, offset int, limit int) ([]entity.StatusChange, int, error)
+	// GetLastStatusChange returns when the status of the entry changed last, not after at unless at is zero, or nil if it never changed.
+	GetLastStatusChange(ctx context.Context, tenantId string, listId int, index int, at time.Time) (*time.Time, error)
+	ApplyDueStatusChanges(ctx context.Context, now time.Time, limit int) ([]entity.ScheduledChange, error)
+	GetScheduledChanges(ctx context.Context, tenantId string, filter entity.StatusChangeFilter, offset int, limit int) ([]entity.ScheduledChange, int, error)
+	GetCredentialEntry(ctx context.Context, tenantId string, ref string) (*entity.CredentialEntry, error)
+	ReserveIndexInCurrentList(ctx context.Context, tenantId string, options entity.ListOptions, expiresAt time.Time) (*entity.Reservation, error)
+	CommitReservation(ctx context.Context, tenantId string, id string, now time.Time, allocation entity.Allocation) (*entity.Reservation, error)
+	ReleaseReservation(ctx context.Context, tenantId string, id string) (*entity.Reservation, error)
+	ReleaseExpiredReservations(ctx context.Context, now time.Time, limit int) ([]entity.Reservation, error)
+	DeleteIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
 	CacheList(ctx context.Context, cacheId string, list []byte) error
 	Ping() bool
 	Close()
 }
 
+// ErrNotApplied marks the entries of an atomic batch which were rolled back because another entry failed.
+var ErrNotApplied = errors.New("entry was not changed because another entry of the batch failed")
+
 // TablePrefix is needed for table name cause of postgres name convention -> no integers allowed
 const TablePrefix = "tenant_id_"
 
@@ -24,7 +47,7 @@ type Database struct {
 	DbConnection
 }
 
-func New(ctx context.Context, config pgPkg.Config, listSizeInBytes int) (*Database, error) {
-	dbConnection, err := newPostgresConnection(config, ctx, listSizeInBytes)
+func New(ctx context.Context, config pgPkg.Config, listSizeInBytes int, defaultOptions entity.ListOptions) (*Database, error) {
+	dbConnection, err := newPostgresConnection(config, ctx, listSizeInBytes, defaultOptions)
 	return &Database{DbConnection: dbConnection}, err
 }
@@ -6,163 +6,1039 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
 	pgPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/db/postgres"
 	errPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
 	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // TODO: queries as constants?
 
+// undefinedTable is the postgres error code of queries on tables which do not exist
+const undefinedTable = "42P01"
+
+// uniqueViolation is the postgres error code of inserts which violate a unique constraint
+const uniqueViolation = "23505"
+
+// listColumns are the columns of a tenant table which are scanned into entity.List
+const listColumns = "listID, list, free, bits, allocated, allocationmode, purpose, version, lastupdate"
+
 type postgresConnection struct {
 	conn            *pgxpool.Pool
 	listSizeInBytes int
+	defaultOptions  entity.ListOptions
+}
+
+func (pc *postgresConnection) Ping() bool {
+	return pc.conn.Ping(context.Background()) == nil
+}
+
+func newPostgresConnection(database pgPkg.Config, ctx context.Context, listSizeInBytes int, defaultOptions entity.ListOptions) (DbConnection, error) {
+	logger := ctxPkg.GetLogger(ctx)
+
+	errChan := make(chan error)
+	go errPkg.LogChan(logger, errChan)
+	conn, err := pgPkg.ConnectRetry(ctx, database, time.Minute, errChan)
+	if err != nil {
+		logger.Error(err, "failed to connect to postgres")
+		os.Exit(1)
+	}
+
+	if err := createServiceTables(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	if err := migrateTenantTables(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	return &postgresConnection{
+		conn:            conn,
+		listSizeInBytes: listSizeInBytes,
+		defaultOptions:  defaultOptions,
+	}, nil
+}
+
+// createServiceTables creates the tables which the service shares across tenants.
+func createServiceTables(ctx context.Context, conn *pgxpool.Pool) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS status_audit (
+			id BIGSERIAL PRIMARY KEY,
+			tenantid TEXT NOT NULL,
+			listid INT NOT NULL,
+			idx INT NOT NULL,
+			oldstatus SMALLINT NOT NULL,
+			newstatus SMALLINT NOT NULL,
+			actor TEXT NOT NULL DEFAULT '',
+			reason TEXT NOT NULL DEFAULT 'unspecified',
+			comment TEXT NOT NULL DEFAULT '',
+			requestid TEXT NOT NULL DEFAULT '',
+			changedat TIMESTAMPTZ NOT NULL DEFAULT now())`,
+		"CREATE INDEX IF NOT EXISTS status_audit_entry ON status_audit (tenantid, listid, idx, changedat)",
+		"CREATE INDEX IF NOT EXISTS status_audit_actor ON status_audit (tenantid, actor, changedat)",
+		`CREATE TABLE IF NOT EXISTS status_history (
+			tenantid TEXT NOT NULL,
+			listid INT NOT NULL,
+			version BIGINT NOT NULL,
+			list BYTEA NOT NULL,
+			bits INT NOT NULL,
+			purpose TEXT NOT NULL,
+			validfrom TIMESTAMPTZ NOT NULL,
+			validuntil TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (tenantid, listid, version))`,
+		"CREATE INDEX IF NOT EXISTS status_history_validity ON status_history (tenantid, listid, validfrom)",
+		`CREATE TABLE IF NOT EXISTS status_schedule (
+			id BIGSERIAL PRIMARY KEY,
+			tenantid TEXT NOT NULL,
+			listid INT NOT NULL,
+			idx INT NOT NULL,
+			action TEXT NOT NULL,
+			reason TEXT NOT NULL DEFAULT 'unspecified',
+			dueat TIMESTAMPTZ NOT NULL,
+			appliedat TIMESTAMPTZ,
+			error TEXT NOT NULL DEFAULT '')`,
+		"CREATE INDEX IF NOT EXISTS status_schedule_due ON status_schedule (dueat) WHERE appliedat IS NULL",
+		"CREATE INDEX IF NOT EXISTS status_schedule_entry ON status_schedule (tenantid, listid, idx)",
+		`CREATE TABLE IF NOT EXISTS credential_refs (
+			tenantid TEXT NOT NULL,
+			ref TEXT NOT NULL,
+			listid INT NOT NULL,
+			idx INT NOT NULL,
+			createdat TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (tenantid, ref))`,
+		`CREATE TABLE IF NOT EXISTS status_reservations (
+			id TEXT PRIMARY KEY,
+			tenantid TEXT NOT NULL,
+			listid INT NOT NULL,
+			idx INT NOT NULL,
+			expiresat TIMESTAMPTZ NOT NULL)`,
+		"CREATE INDEX IF NOT EXISTS status_reservations_expiry ON status_reservations (expiresat)",
+		`CREATE TABLE IF NOT EXISTS status_requests (
+			tenantid TEXT NOT NULL,
+			idempotencykey TEXT NOT NULL,
+			fingerprint TEXT NOT NULL,
+			listids INT[] NOT NULL DEFAULT '{}',
+			indices INT[] NOT NULL DEFAULT '{}',
+			createdat TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (tenantid, idempotencykey))`,
+		"CREATE INDEX IF NOT EXISTS status_requests_age ON status_requests (createdat)",
+		// the audit log is append only
+		"CREATE OR REPLACE RULE status_audit_no_update AS ON UPDATE TO status_audit DO INSTEAD NOTHING",
+		"CREATE OR REPLACE RULE status_audit_no_delete AS ON DELETE TO status_audit DO INSTEAD NOTHING",
+	}
+
+	for _, query := range queries {
+		if _, err := conn.Exec(ctx, query); err != nil {
+			return fmt.Errorf("could not create service tables: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (pc *postgresConnection) GetStatusList(ctx context.Context, tenantId string, listId int) (*entity.List, error) {
+	tx, err := pc.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:       pgx.ReadCommitted,
+		AccessMode:     pgx.ReadWrite,
+		DeferrableMode: pgx.NotDeferrable,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("error creating transaction: %w", err)
+	}
+
+	tableName, err := createTableName(tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s WHERE listID=%s LIMIT 1", listColumns, tableName, strconv.Itoa(listId))
+
+	rows, err := tx.Query(ctx, selectQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error while select current list from the database: %w", listError(err, listId))
+	}
+
+	databaseRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.List])
+	if err != nil {
+		return nil, fmt.Errorf("error while collecting current list from rows: %w", listError(err, listId))
+	}
+
+	if len(databaseRows) == 0 {
+		return nil, fmt.Errorf("%w: listId %d", entity.ErrListNotFound, listId)
+	}
+
+	currentList := databaseRows[0]
+
+	return &currentList, nil
+}
+
+// GetStatusListAt returns the list as it was at the instant. Lists which did not exist or whose version at the instant is not known are not found.
+func (pc *postgresConnection) GetStatusListAt(ctx context.Context, tenantId string, listId int, at time.Time) (*entity.List, error) {
+	list, err := pc.GetStatusList(ctx, tenantId, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !list.LastUpdate.After(at) {
+		return list, nil
+	}
+
+	const selectQuery = "SELECT version, list, bits, purpose, validfrom FROM status_history WHERE tenantid = $1 AND listid = $2 AND validfrom <= $3 AND validuntil > $3 ORDER BY version DESC LIMIT 1"
+	err = pc.conn.QueryRow(ctx, selectQuery, tenantId, listId, at).Scan(&list.Version, &list.List, &list.Bits, &list.Purpose, &list.LastUpdate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: listId %d has no version at %s", entity.ErrListNotFound, listId, at.Format(time.RFC3339))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error selecting list version from the history: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetStatusLists returns a page of the lists of the tenant ordered by listId and the number of all lists. Tenants without table have no lists.
+func (pc *postgresConnection) GetStatusLists(ctx context.Context, tenantId string, offset int, limit int) ([]entity.List, int, error) {
+	tableName, err := createTableName(tenantId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var exists bool
+	if err := pc.conn.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", tableName).Scan(&exists); err != nil {
+		return nil, 0, fmt.Errorf("error query for table name: %w", err)
+	}
+
+	if !exists {
+		return []entity.List{}, 0, nil
+	}
+
+	var total int
+	if err := pc.conn.QueryRow(ctx, fmt.Sprintf("SELECT count(*) FROM %s", tableName)).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error while counting lists: %w", err)
+	}
+
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s ORDER BY listID LIMIT $1 OFFSET $2", listColumns, tableName)
+	rows, err := pc.conn.Query(ctx, selectQuery, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error while select lists from the database: %w", err)
+	}
+
+	lists, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.List])
+	if err != nil {
+		return nil, 0, fmt.Errorf("error while collecting lists from rows: %w", err)
+	}
+
+	return lists, total, nil
 }
 
-func (pc *postgresConnection) Ping() bool {
-	return pc.conn.Ping(context.Background()) == nil
+func (pc *postgresConnection) AllocateIndexInCurrentList(ctx context.Context, tenantId string, options entity.ListOptions) (*entity.StatusData, error) {
+	statusData, err := pc.AllocateIndicesInCurrentList(ctx, tenantId, options, 1, entity.Allocation{})
+	if err != nil {
+		return nil, err
+	}
+
+	return statusData[0], nil
+}
+
+// AllocateIndicesInCurrentList allocates count indices in one transaction. The actions and credential references of the allocation are stored in the same transaction. A repeated request with the idempotency key of the allocation returns the entries of the first request.
+func (pc *postgresConnection) AllocateIndicesInCurrentList(ctx context.Context, tenantId string, options entity.ListOptions, count int, allocation entity.Allocation) ([]*entity.StatusData, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	if count < 1 {
+		return nil, fmt.Errorf("invalid number of indices: %d", count)
+	}
+
+	if len(allocation.CredentialRefs) > 0 && len(allocation.CredentialRefs) != count {
+		return nil, fmt.Errorf("invalid number of credential references: %d for %d indices", len(allocation.CredentialRefs), count)
+	}
+
+	tx, err := pc.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:       pgx.ReadCommitted,
+		AccessMode:     pgx.ReadWrite,
+		DeferrableMode: pgx.NotDeferrable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tableName, err := createTableName(tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	if allocation.Idempotency != nil {
+		repeated, err := claimIdempotencyKey(ctx, tx, tenantId, *allocation.Idempotency)
+		if err != nil {
+			return nil, err
+		}
+		if repeated != nil {
+			return repeated, nil
+		}
+	}
+
+	statusData, err := pc.allocateIndices(ctx, tx, tableName, options, count)
+	if err != nil {
+		return nil, err
+	}
+
+	if allocation.Idempotency != nil {
+		if err := storeIdempotentAllocation(ctx, tx, tenantId, *allocation.Idempotency, statusData); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := scheduleActions(ctx, tx, tenantId, statusData, allocation.Actions); err != nil {
+		return nil, err
+	}
+
+	if err := registerCredentialRefs(ctx, tx, tenantId, statusData, allocation.CredentialRefs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+
+	return statusData, nil
+}
+
+// allocateIndices allocates count indices in the transaction. It fills the lists with free indices first and creates new lists for the rest.
+func (pc *postgresConnection) allocateIndices(ctx context.Context, tx pgx.Tx, tableName string, options entity.ListOptions, count int) ([]*entity.StatusData, error) {
+	statusData := make([]*entity.StatusData, 0, count)
+
+	// allocate in current lists, lists filled by this transaction no longer match free > 0
+	for len(statusData) < count {
+		selectQuery := fmt.Sprintf("SELECT %s FROM %s WHERE free > 0 AND bits = $1 AND allocationmode = $2 AND purpose = $3 FOR UPDATE LIMIT 1", listColumns, tableName)
+		rows, err := tx.Query(ctx, selectQuery, options.Bits, options.AllocationMode, options.Purpose)
+		if err != nil {
+			return nil, fmt.Errorf("error while select current list from the database: %w", err)
+		}
+		// not optimized for performance cause of reflection
+		databaseRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.List])
+		if err != nil {
+			return nil, fmt.Errorf("error while collecting current list from rows: %w", err)
+		}
+
+		if len(databaseRows) == 0 {
+			break
+		}
+
+		currentList := databaseRows[0]
+
+		indices, err := currentList.AllocateIndices(count - len(statusData))
+		if err != nil {
+			return nil, fmt.Errorf("error allocating free indices from current list: %w", err)
+		}
+
+		updateQuery := fmt.Sprintf("UPDATE %s SET free = $1, allocated = $2 WHERE listID = $3", tableName)
+		if _, err := tx.Exec(ctx, updateQuery, currentList.Free, currentList.Allocated, currentList.ListId); err != nil {
+			return nil, fmt.Errorf("error updating list in the database: %w", err)
+		}
+
+		for _, index := range indices {
+			statusData = append(statusData, entity.NewStatusData(index, currentList.ListId))
+		}
+	}
+
+	// no current list -> create new ones and allocate the remaining indices
+	for len(statusData) < count {
+		newList := entity.NewListWithOptions(pc.listSizeInBytes, options)
+
+		indices, err := newList.AllocateIndices(count - len(statusData))
+		if err != nil {
+			return nil, fmt.Errorf("error allocating free indices from new list: %w", err)
+		}
+
+		if len(indices) == 0 {
+			return nil, fmt.Errorf("error allocating free indices from new list: %w", entity.ErrFullyAllocated)
+		}
+
+		insertQuery := fmt.Sprintf("INSERT INTO %s (list, free, bits, allocated, allocationmode, purpose) VALUES ($1, $2, $3, $4, $5, $6) RETURNING listID", tableName)
+		var listId int
+		if err = tx.
+			QueryRow(ctx, insertQuery, newList.List, newList.Free, newList.Bits, newList.Allocated, newList.AllocationMode, newList.Purpose).
+			Scan(&listId); err != nil {
+			return nil, fmt.Errorf("error inserting new list into the database: %w", err)
+		}
+
+		for _, index := range indices {
+			statusData = append(statusData, entity.NewStatusData(index, listId))
+		}
+	}
+
+	return statusData, nil
+}
+
+// claimIdempotencyKey stores the key for the allocation of this transaction. If the key was used within its window, the entries of the earlier allocation are returned instead. Concurrent requests with the same key wait until the first one ends.
+func claimIdempotencyKey(ctx context.Context, tx pgx.Tx, tenantId string, key entity.IdempotencyKey) ([]*entity.StatusData, error) {
+	now := time.Now()
+
+	const deleteQuery = "DELETE FROM status_requests WHERE tenantid = $1 AND idempotencykey = $2 AND createdat <= $3"
+	if _, err := tx.Exec(ctx, deleteQuery, tenantId, key.Key, now.Add(-key.Window)); err != nil {
+		return nil, fmt.Errorf("error deleting expired idempotency key: %w", err)
+	}
+
+	const insertQuery = "INSERT INTO status_requests (tenantid, idempotencykey, fingerprint, createdat) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING"
+	tag, err := tx.Exec(ctx, insertQuery, tenantId, key.Key, key.Fingerprint, now)
+	if err != nil {
+		return nil, fmt.Errorf("error storing idempotency key: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, nil
+	}
+
+	var fingerprint string
+	var listIds, indices []int
+	const selectQuery = "SELECT fingerprint, listids, indices FROM status_requests WHERE tenantid = $1 AND idempotencykey = $2"
+	if err := tx.QueryRow(ctx, selectQuery, tenantId, key.Key).Scan(&fingerprint, &listIds, &indices); err != nil {
+		return nil, fmt.Errorf("error selecting allocation of idempotency key: %w", err)
+	}
+
+	if fingerprint != key.Fingerprint {
+		return nil, fmt.Errorf("%w: %s", entity.ErrIdempotencyKeyReused, key.Key)
+	}
+
+	statusData := make([]*entity.StatusData, len(indices))
+	for i := range indices {
+		statusData[i] = entity.NewStatusData(indices[i], listIds[i])
+	}
+	return statusData, nil
+}
+
+// storeIdempotentAllocation keeps the allocated entries with the key, so that repetitions of the request return them.
+func storeIdempotentAllocation(ctx context.Context, tx pgx.Tx, tenantId string, key entity.IdempotencyKey, statusData []*entity.StatusData) error {
+	listIds := make([]int, len(statusData))
+	indices := make([]int, len(statusData))
+	for i, data := range statusData {
+		listIds[i], indices[i] = data.ListId, data.Index
+	}
+
+	const updateQuery = "UPDATE status_requests SET listids = $3, indices = $4 WHERE tenantid = $1 AND idempotencykey = $2"
+	if _, err := tx.Exec(ctx, updateQuery, tenantId, key.Key, listIds, indices); err != nil {
+		return fmt.Errorf("error storing allocation of idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKeys forgets the keys of requests which were created before, their repetitions allocate new entries.
+func (pc *postgresConnection) DeleteIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := pc.conn.Exec(ctx, "DELETE FROM status_requests WHERE createdat <= $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting idempotency keys: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// scheduleActions plans the actions for every allocated entry.
+func scheduleActions(ctx context.Context, tx pgx.Tx, tenantId string, statusData []*entity.StatusData, actions []entity.ScheduledAction) error {
+	if len(actions) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, 0, len(statusData)*len(actions))
+	for _, data := range statusData {
+		for _, action := range actions {
+			rows = append(rows, []interface{}{tenantId, data.ListId, data.Index, action.Action, action.Reason, action.DueAt})
+		}
+	}
+
+	columns := []string{"tenantid", "listid", "idx", "action", "reason", "dueat"}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"status_schedule"}, columns, pgx.CopyFromRows(rows)); err != nil {
+		return fmt.Errorf("error scheduling status changes: %w", err)
+	}
+
+	return nil
+}
+
+// registerCredentialRefs maps the references to the allocated entries in allocation order.
+func registerCredentialRefs(ctx context.Context, tx pgx.Tx, tenantId string, statusData []*entity.StatusData, refs []string) error {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, len(refs))
+	for i, ref := range refs {
+		rows[i] = []interface{}{tenantId, ref, statusData[i].ListId, statusData[i].Index}
+	}
+
+	columns := []string{"tenantid", "ref", "listid", "idx"}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"credential_refs"}, columns, pgx.CopyFromRows(rows)); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return fmt.Errorf("%w: %s", entity.ErrCredentialRefExists, pgErr.Detail)
+		}
+		return fmt.Errorf("error registering credential references: %w", err)
+	}
+
+	return nil
+}
+
+// GetCredentialEntry resolves the reference of a credential of the tenant to its entry.
+func (pc *postgresConnection) GetCredentialEntry(ctx context.Context, tenantId string, ref string) (*entity.CredentialEntry, error) {
+	const selectQuery = "SELECT ref, listid, idx, createdat FROM credential_refs WHERE tenantid = $1 AND ref = $2"
+	rows, err := pc.conn.Query(ctx, selectQuery, tenantId, ref)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting credential reference: %w", err)
+	}
+
+	entry, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[entity.CredentialEntry])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", entity.ErrCredentialNotFound, ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading credential reference: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// reservationColumns are the columns of status_reservations which are scanned into entity.Reservation
+const reservationColumns = "id, tenantid, listid, idx, expiresat"
+
+// ReserveIndexInCurrentList allocates an index like AllocateIndexInCurrentList and holds it for the issuance until expiresAt.
+func (pc *postgresConnection) ReserveIndexInCurrentList(ctx context.Context, tenantId string, options entity.ListOptions, expiresAt time.Time) (*entity.Reservation, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := pc.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:       pgx.ReadCommitted,
+		AccessMode:     pgx.ReadWrite,
+		DeferrableMode: pgx.NotDeferrable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tableName, err := createTableName(tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	statusData, err := pc.allocateIndices(ctx, tx, tableName, options, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation := &entity.Reservation{
+		Id:        uuid.NewString(),
+		TenantId:  tenantId,
+		ListId:    statusData[0].ListId,
+		Index:     statusData[0].Index,
+		ExpiresAt: expiresAt,
+	}
+
+	const insertQuery = "INSERT INTO status_reservations (id, tenantid, listid, idx, expiresat) VALUES ($1, $2, $3, $4, $5)"
+	if _, err := tx.Exec(ctx, insertQuery, reservation.Id, reservation.TenantId, reservation.ListId, reservation.Index, reservation.ExpiresAt); err != nil {
+		return nil, fmt.Errorf("error inserting reservation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// CommitReservation keeps the reserved index allocated for good and stores the credential references of the allocation with it. Expired reservations are left to the sweeper.
+func (pc *postgresConnection) CommitReservation(ctx context.Context, tenantId string, id string, now time.Time, allocation entity.Allocation) (*entity.Reservation, error) {
+	tx, err := pc.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:       pgx.ReadCommitted,
+		AccessMode:     pgx.ReadWrite,
+		DeferrableMode: pgx.NotDeferrable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	reservation, err := deleteReservation(ctx, tx, tenantId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if reservation.Expired(now) {
+		return nil, fmt.Errorf("%w: %s at %s", entity.ErrReservationExpired, id, reservation.ExpiresAt.Format(time.RFC3339))
+	}
+
+	statusData := []*entity.StatusData{entity.NewStatusData(reservation.Index, reservation.ListId)}
+	if err := registerCredentialRefs(ctx, tx, tenantId, statusData, allocation.CredentialRefs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// ReleaseReservation returns the reserved index to the free indices of its list.
+func (pc *postgresConnection) ReleaseReservation(ctx context.Context, tenantId string, id string) (*entity.Reservation, error) {
+	tx, err := pc.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:       pgx.ReadCommitted,
+		AccessMode:     pgx.ReadWrite,
+		DeferrableMode: pgx.NotDeferrable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	reservation, err := deleteReservation(ctx, tx, tenantId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes, err := releaseIndices(ctx, tx, tenantId, reservation.ListId, []*entity.Reservation{reservation})
+	if err != nil {
+		return nil, err
+	}
+	if outcomes[0] != nil {
+		return nil, outcomes[0]
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// ReleaseExpiredReservations releases up to limit reservations which expired at now and returns them. Rows locked by other replicas are skipped. Entries whose status changed while they were reserved stay allocated.
+func (pc *postgresConnection) ReleaseExpiredReservations(ctx context.Context, now time.Time, limit int) ([]entity.Reservation, error) {
+	tx, err := pc.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:       pgx.ReadCommitted,
+		AccessMode:     pgx.ReadWrite,
+		DeferrableMode: pgx.NotDeferrable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// lists are locked in ascending order like in batch revocations, so both can not deadlock
+	selectQuery := fmt.Sprintf("SELECT %s FROM status_reservations WHERE expiresat <= $1 ORDER BY tenantid, listid, idx LIMIT $2 FOR UPDATE SKIP LOCKED", reservationColumns)
+	rows, err := tx.Query(ctx, selectQuery, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting expired reservations: %w", err)
+	}
+	reservations, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[entity.Reservation])
+	if err != nil {
+		return nil, fmt.Errorf("error reading expired reservations: %w", err)
+	}
+
+	ids := make([]string, len(reservations))
+	for i, reservation := range reservations {
+		ids[i] = reservation.Id
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM status_reservations WHERE id = ANY($1)", ids); err != nil {
+		return nil, fmt.Errorf("error deleting expired reservations: %w", err)
+	}
+
+	for start := 0; start < len(reservations); {
+		end := start
+		for end < len(reservations) && reservations[end].TenantId == reservations[start].TenantId && reservations[end].ListId == reservations[start].ListId {
+			end++
+		}
+
+		// entries which can not be released stay allocated, the reservation ends anyway
+		_, err := releaseIndices(ctx, tx, reservations[start].TenantId, reservations[start].ListId, reservations[start:end])
+		if err != nil && !errors.Is(err, entity.ErrListNotFound) {
+			return nil, err
+		}
+
+		start = end
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
+	}
+
+	released := make([]entity.Reservation, len(reservations))
+	for i, reservation := range reservations {
+		released[i] = *reservation
+	}
+	return released, nil
+}
+
+// deleteReservation removes the reservation of the tenant and returns it.
+func deleteReservation(ctx context.Context, tx pgx.Tx, tenantId string, id string) (*entity.Reservation, error) {
+	deleteQuery := fmt.Sprintf("DELETE FROM status_reservations WHERE tenantid = $1 AND id = $2 RETURNING %s", reservationColumns)
+	rows, err := tx.Query(ctx, deleteQuery, tenantId, id)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting reservation: %w", err)
+	}
+
+	reservation, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[entity.Reservation])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", entity.ErrReservationNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading reservation: %w", err)
+	}
+
+	return reservation, nil
 }
 
-func newPostgresConnection(database pgPkg.Config, ctx context.Context, listSizeInBytes int) (DbConnection, error) {
-	logger := ctxPkg.GetLogger(ctx)
+// releaseIndices returns the indices of the reservations, which all belong to the same list, to its free indices. It reports per reservation whether its index could be released.
+func releaseIndices(ctx context.Context, tx pgx.Tx, tenantId string, listId int, reservations []*entity.Reservation) ([]error, error) {
+	tableName, err := createTableName(tenantId)
+	if err != nil {
+		return nil, err
+	}
 
-	errChan := make(chan error)
-	go errPkg.LogChan(logger, errChan)
-	conn, err := pgPkg.ConnectRetry(ctx, database, time.Minute, errChan)
+	list, err := lockList(ctx, tx, tableName, listId)
 	if err != nil {
-		logger.Error(err, "failed to connect to postgres")
-		os.Exit(1)
+		return nil, err
 	}
 
-	return &postgresConnection{
-		conn:            conn,
-		listSizeInBytes: listSizeInBytes,
-	}, nil
+	outcomes := make([]error, len(reservations))
+	for i, reservation := range reservations {
+		outcomes[i] = list.ReleaseIndex(reservation.Index)
+	}
+
+	updateQuery := fmt.Sprintf("UPDATE %s SET free = $1, allocated = $2 WHERE listID = $3", tableName)
+	if _, err := tx.Exec(ctx, updateQuery, list.Free, list.Allocated, list.ListId); err != nil {
+		return nil, fmt.Errorf("error updating list in the database: %w", err)
+	}
+
+	return outcomes, nil
 }
 
-func (pc *postgresConnection) GetStatusList(ctx context.Context, tenantId string, listId int) ([]byte, error) {
+// ApplyDueStatusChanges applies up to limit scheduled changes which are due at now and returns them with their outcome. Rows locked by other replicas are skipped, so every change is applied once. Changes which can not be applied, e.g. because the entry is already revoked, are closed with their error.
+func (pc *postgresConnection) ApplyDueStatusChanges(ctx context.Context, now time.Time, limit int) ([]entity.ScheduledChange, error) {
 	tx, err := pc.conn.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:       pgx.ReadCommitted,
 		AccessMode:     pgx.ReadWrite,
 		DeferrableMode: pgx.NotDeferrable,
 	})
-
 	if err != nil {
 		return nil, fmt.Errorf("could not start transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	// lists are locked in ascending order like in batch revocations, so both can not deadlock
+	const selectQuery = "SELECT id, tenantid, listid, idx, action, reason, dueat, appliedat, error FROM status_schedule WHERE appliedat IS NULL AND dueat <= $1 ORDER BY tenantid, listid, id LIMIT $2 FOR UPDATE SKIP LOCKED"
+	rows, err := tx.Query(ctx, selectQuery, now, limit)
 	if err != nil {
-		return nil, fmt.Errorf("error creating transaction: %w", err)
+		return nil, fmt.Errorf("error selecting due status changes: %w", err)
+	}
+	changes, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.ScheduledChange])
+	if err != nil {
+		return nil, fmt.Errorf("error reading due status changes: %w", err)
+	}
+
+	for start := 0; start < len(changes); {
+		end := start
+		for end < len(changes) && changes[end].TenantId == changes[start].TenantId && changes[end].ListId == changes[start].ListId {
+			end++
+		}
+
+		if err := applyScheduledChangesToList(ctx, tx, changes[start:end]); err != nil {
+			return nil, err
+		}
+
+		start = end
+	}
+
+	const updateQuery = "UPDATE status_schedule SET appliedat = now(), error = $2 WHERE id = $1"
+	for i := range changes {
+		if _, err := tx.Exec(ctx, updateQuery, changes[i].Id, changes[i].Error); err != nil {
+			return nil, fmt.Errorf("error closing scheduled status change: %w", err)
+		}
+		changes[i].AppliedAt = &now
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error commiting transaction: %w", err)
 	}
 
+	return changes, nil
+}
+
+// applyScheduledChangesToList applies the changes which all belong to the same list and records the errors of single changes in them.
+func applyScheduledChangesToList(ctx context.Context, tx pgx.Tx, changes []entity.ScheduledChange) error {
+	tenantId, listId := changes[0].TenantId, changes[0].ListId
+
 	tableName, err := createTableName(tenantId)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	selectQuery := fmt.Sprintf("SELECT listID,list, free FROM %s WHERE listID=%s LIMIT 1", tableName, strconv.Itoa(listId))
-
-	rows, err := tx.Query(ctx, selectQuery)
+	list, err := lockList(ctx, tx, tableName, listId)
+	if errors.Is(err, entity.ErrListNotFound) {
+		for i := range changes {
+			changes[i].Error = err.Error()
+		}
+		return nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error while select current list from the database: %w", err)
+		return err
 	}
 
-	databaseRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.List])
+	audit := make([]entity.StatusChange, 0, len(changes))
+	for i := range changes {
+		entry := entity.Entry{ListId: listId, Index: changes[i].Index}
+
+		oldStatus, err := list.StatusAtIndex(entry.Index)
+		if err == nil {
+			err = changes[i].Apply(list)
+		}
+		if err != nil {
+			changes[i].Error = err.Error()
+			continue
+		}
+
+		// e.g. expiresAt after revokeAt finds the entry revoked already, the audit log only records changes
+		newStatus, _ := list.StatusAtIndex(entry.Index)
+		if newStatus == oldStatus {
+			continue
+		}
+		audit = append(audit, entity.NewStatusChange(tenantId, entry, oldStatus, newStatus, changes[i].ChangeReason()))
+	}
+
+	if len(audit) == 0 {
+		return nil
+	}
+
+	if err := storeListStatus(ctx, tx, tenantId, tableName, list); err != nil {
+		return err
+	}
+
+	for _, change := range audit {
+		if err := recordStatusChange(ctx, tx, change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetScheduledChanges returns a page of the pending scheduled changes of the tenant ordered by due time and the number of all pending changes which match the filter.
+func (pc *postgresConnection) GetScheduledChanges(ctx context.Context, tenantId string, filter entity.StatusChangeFilter, offset int, limit int) ([]entity.ScheduledChange, int, error) {
+	conditions := []string{"tenantid = $1", "appliedat IS NULL"}
+	args := []interface{}{tenantId}
+
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ListId != nil {
+		addCondition("listid = $%d", *filter.ListId)
+	}
+	if filter.Index != nil {
+		addCondition("idx = $%d", *filter.Index)
+	}
+	if !filter.From.IsZero() {
+		addCondition("dueat >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("dueat < $%d", filter.To)
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := pc.conn.QueryRow(ctx, "SELECT count(*) FROM status_schedule WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting scheduled status changes: %w", err)
+	}
+
+	selectQuery := fmt.Sprintf("SELECT id, tenantid, listid, idx, action, reason, dueat, appliedat, error FROM status_schedule WHERE %s ORDER BY dueat, id OFFSET $%d LIMIT $%d", where, len(args)+1, len(args)+2)
+	rows, err := pc.conn.Query(ctx, selectQuery, append(args, offset, limit)...)
 	if err != nil {
-		return nil, fmt.Errorf("error while collecting current list from rows: %w", err)
+		return nil, 0, fmt.Errorf("error selecting scheduled status changes: %w", err)
 	}
 
-	if len(databaseRows) == 0 {
-		return nil, errors.New("list not found")
+	changes, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.ScheduledChange])
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading scheduled status changes: %w", err)
 	}
 
-	currentList := databaseRows[0]
+	return changes, total, nil
+}
+
+func (pc *postgresConnection) RevokeCredentialInSpecifiedList(ctx context.Context, tenantId string, listId int, index int, reason entity.ChangeReason) error {
+	return pc.changeStatusInSpecifiedList(ctx, tenantId, entity.Entry{ListId: listId, Index: index}, reason, func(list *entity.List) error {
+		return list.RevokeAtIndex(index)
+	})
+}
+
+func (pc *postgresConnection) SuspendCredentialInSpecifiedList(ctx context.Context, tenantId string, listId int, index int, reason entity.ChangeReason) error {
+	return pc.changeStatusInSpecifiedList(ctx, tenantId, entity.Entry{ListId: listId, Index: index}, reason, func(list *entity.List) error {
+		return list.SuspendAtIndex(index)
+	})
+}
+
+func (pc *postgresConnection) UnsuspendCredentialInSpecifiedList(ctx context.Context, tenantId string, listId int, index int, reason entity.ChangeReason) error {
+	return pc.changeStatusInSpecifiedList(ctx, tenantId, entity.Entry{ListId: listId, Index: index}, reason, func(list *entity.List) error {
+		return list.UnsuspendAtIndex(index)
+	})
+}
 
-	return currentList.List, nil
+func (pc *postgresConnection) UpdateStatusInSpecifiedList(ctx context.Context, tenantId string, listId int, index int, status uint8, reason entity.ChangeReason) error {
+	return pc.changeStatusInSpecifiedList(ctx, tenantId, entity.Entry{ListId: listId, Index: index}, reason, func(list *entity.List) error {
+		return list.SetStatusAtIndex(index, status)
+	})
 }
 
-func (pc *postgresConnection) AllocateIndexInCurrentList(ctx context.Context, tenantId string) (*entity.StatusData, error) {
+// changeStatusInSpecifiedList locks the list of the entry, applies change to it, stores the result and records the change in the audit log.
+func (pc *postgresConnection) changeStatusInSpecifiedList(ctx context.Context, tenantId string, entry entity.Entry, reason entity.ChangeReason, change func(list *entity.List) error) error {
 	tx, err := pc.conn.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:       pgx.ReadCommitted,
 		AccessMode:     pgx.ReadWrite,
 		DeferrableMode: pgx.NotDeferrable,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("could not start transaction: %w", err)
+		return fmt.Errorf("could not start transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	tableName, err := createTableName(tenantId)
 	if err != nil {
-		return nil, fmt.Errorf("error creating transaction: %w", err)
+		return err
 	}
 
-	tableName, err := createTableName(tenantId)
+	specifiedList, err := lockList(ctx, tx, tableName, entry.ListId)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	selectQuery := fmt.Sprintf("SELECT listID, list, free FROM %s WHERE free > 0 FOR UPDATE LIMIT 1", tableName)
-	rows, err := tx.Query(ctx, selectQuery)
+	oldStatus, err := specifiedList.StatusAtIndex(entry.Index)
 	if err != nil {
-		return nil, fmt.Errorf("error while select current list from the database: %w", err)
+		return fmt.Errorf("error changing status in specified list: %w", err)
 	}
-	// not optimized for performance cause of reflection
-	databaseRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.List])
+
+	if err := change(specifiedList); err != nil {
+		return fmt.Errorf("error changing status in specified list: %w", err)
+	}
+
+	newStatus, _ := specifiedList.StatusAtIndex(entry.Index)
+
+	if err := storeListStatus(ctx, tx, tenantId, tableName, specifiedList); err != nil {
+		return err
+	}
+
+	if err := recordStatusChange(ctx, tx, entity.NewStatusChange(tenantId, entry, oldStatus, newStatus, reason)); err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error while collecting current list from rows: %w", err)
+		return fmt.Errorf("error commiting transaction: %w", err)
 	}
 
-	if len(databaseRows) == 0 {
-		// no current list -> create new one and allocate index
-		newList := entity.NewList(pc.listSizeInBytes)
+	return nil
+}
 
-		index, err := newList.AllocateNextFreeIndex()
-		if err != nil {
-			return nil, fmt.Errorf("error allocating next free index from new list: %w", err)
-		}
+// RevokeCredentialsInSpecifiedLists revokes the entries with one transaction per list. It returns the outcome per entry, nil for revoked entries and entity.ErrAlreadyRevoked for entries which were revoked before or occur twice. If atomic is set, all lists are changed in one transaction which is rolled back when any entry fails.
+func (pc *postgresConnection) RevokeCredentialsInSpecifiedLists(ctx context.Context, tenantId string, entries []entity.Entry, atomic bool, reason entity.ChangeReason) ([]error, error) {
+	tableName, err := createTableName(tenantId)
+	if err != nil {
+		return nil, err
+	}
 
-		insertQuery := fmt.Sprintf("INSERT INTO %s (list, free) VALUES ($1, $2) RETURNING listID", tableName)
-		var listId int
-		if err = tx.
-			QueryRow(ctx, insertQuery, newList.List, newList.Free).
-			Scan(&listId); err != nil {
-			return nil, fmt.Errorf("error inserting new list into the database: %w", err)
-		}
+	positions := make(map[int][]int)
+	for position, entry := range entries {
+		positions[entry.ListId] = append(positions[entry.ListId], position)
+	}
 
-		if err := tx.Commit(ctx); err != nil {
-			return nil, fmt.Errorf("error commiting transaction: %w", err)
+	listIds := make([]int, 0, len(positions))
+	for listId := range positions {
+		listIds = append(listIds, listId)
+	}
+	// lists are locked in ascending order, so concurrent batches can not deadlock
+	sort.Ints(listIds)
+
+	results := make([]error, len(entries))
+
+	batch := revokeBatch{tenantId: tenantId, tableName: tableName, entries: entries, results: results, reason: reason}
+
+	if !atomic {
+		for _, listId := range listIds {
+			if err := pc.revokeEntriesInOwnTransaction(ctx, batch, listId, positions[listId]); err != nil {
+				for _, position := range positions[listId] {
+					if results[position] == nil {
+						results[position] = err
+					}
+				}
+			}
 		}
 
-		return entity.NewStatusData(index, listId), nil
+		return results, nil
 	}
 
-	// allocate index in current list
-	currentList := databaseRows[0]
-
-	index, err := currentList.AllocateNextFreeIndex()
+	tx, err := pc.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:       pgx.ReadCommitted,
+		AccessMode:     pgx.ReadWrite,
+		DeferrableMode: pgx.NotDeferrable,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error allocating next free index from current list: %w", err)
+		return nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, listId := range listIds {
+		if err := revokeEntriesInList(ctx, tx, batch, listId, positions[listId]); err != nil {
+			for _, position := range positions[listId] {
+				results[position] = err
+			}
+		}
 	}
 
-	updateQuery := fmt.Sprintf("UPDATE %s%s SET list = $1, free = $2 WHERE listID = $3", TablePrefix, tenantId)
-	if _, err := tx.Exec(ctx, updateQuery, currentList.List, currentList.Free, currentList.ListId); err != nil {
-		return nil, fmt.Errorf("error updating list in the database: %w", err)
+	failed := false
+	for _, result := range results {
+		failed = failed || result != nil
+	}
+
+	if failed {
+		for position, result := range results {
+			if result == nil {
+				results[position] = ErrNotApplied
+			}
+		}
+
+		return results, nil
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("error commiting transaction: %w", err)
 	}
 
-	return entity.NewStatusData(index, currentList.ListId), nil
+	return results, nil
 }
 
-func (pc *postgresConnection) RevokeCredentialInSpecifiedList(ctx context.Context, tenantId string, listId int, index int) error {
+// revokeBatch carries the entries of a batch revocation, their outcome and why they are revoked.
+type revokeBatch struct {
+	tenantId  string
+	tableName string
+	entries   []entity.Entry
+	results   []error
+	reason    entity.ChangeReason
+}
+
+func (pc *postgresConnection) revokeEntriesInOwnTransaction(ctx context.Context, batch revokeBatch, listId int, positions []int) error {
 	tx, err := pc.conn.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:       pgx.ReadCommitted,
 		AccessMode:     pgx.ReadWrite,
@@ -173,44 +1049,178 @@ func (pc *postgresConnection) RevokeCredentialInSpecifiedList(ctx context.Contex
 	}
 	defer tx.Rollback(ctx)
 
-	tableName, err := createTableName(tenantId)
+	if err := revokeEntriesInList(ctx, tx, batch, listId, positions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error commiting transaction: %w", err)
+	}
+
+	return nil
+}
+
+// revokeEntriesInList revokes the entries at positions which all belong to the list and records their outcome in the results of the batch.
+func revokeEntriesInList(ctx context.Context, tx pgx.Tx, batch revokeBatch, listId int, positions []int) error {
+	list, err := lockList(ctx, tx, batch.tableName, listId)
+	if err != nil {
+		return err
+	}
+
+	changes := make([]entity.StatusChange, 0, len(positions))
+	for _, position := range positions {
+		entry := batch.entries[position]
+
+		oldStatus, err := list.StatusAtIndex(entry.Index)
+		if err == nil {
+			err = revokeOnce(list, entry.Index)
+		}
+		if err != nil {
+			batch.results[position] = err
+			continue
+		}
+
+		newStatus, _ := list.StatusAtIndex(entry.Index)
+		changes = append(changes, entity.NewStatusChange(batch.tenantId, entry, oldStatus, newStatus, batch.reason))
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := storeListStatus(ctx, tx, batch.tenantId, batch.tableName, list); err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if err := recordStatusChange(ctx, tx, change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// revokeOnce revokes the entry at index and fails with entity.ErrAlreadyRevoked if it is revoked already, so that the batch reports and audits only entries which changed.
+func revokeOnce(list *entity.List, index int) error {
+	revoked, err := list.IsRevoked(index)
 	if err != nil {
 		return err
 	}
+	if revoked {
+		return entity.ErrAlreadyRevoked
+	}
+	return list.RevokeAtIndex(index)
+}
 
-	fmt.Println(tableName)
-	selectQuery := fmt.Sprintf("SELECT listID, list, free FROM %s WHERE listID = $1 FOR UPDATE LIMIT 1", tableName)
+// lockList selects the list for update.
+func lockList(ctx context.Context, tx pgx.Tx, tableName string, listId int) (*entity.List, error) {
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s WHERE listID = $1 FOR UPDATE LIMIT 1", listColumns, tableName)
 	rows, err := tx.Query(ctx, selectQuery, listId)
 	if err != nil {
-		return fmt.Errorf("error while select specified list from the database: %w", err)
+		return nil, fmt.Errorf("error while select specified list from the database: %w", listError(err, listId))
 	}
 	databaseRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.List])
 	if err != nil {
-		return fmt.Errorf("error while getting specified list from rows: %w", err)
+		return nil, fmt.Errorf("error while getting specified list from rows: %w", listError(err, listId))
 	}
 
 	if len(databaseRows) == 0 {
-		return fmt.Errorf("listId %d does not exist in database", listId)
+		return nil, fmt.Errorf("%w: listId %d does not exist in database", entity.ErrListNotFound, listId)
 	}
 
-	specifiedList := databaseRows[0]
+	return &databaseRows[0], nil
+}
 
-	specifiedList.RevokeAtIndex(index)
+// storeListStatus stores the status bits of the list and increases its version. The replaced version is kept in the history of the list.
+func storeListStatus(ctx context.Context, tx pgx.Tx, tenantId string, tableName string, list *entity.List) error {
+	historyQuery := fmt.Sprintf("INSERT INTO status_history (tenantid, listid, version, list, bits, purpose, validfrom, validuntil) SELECT $1, listID, version, list, bits, purpose, lastupdate, now() FROM %s WHERE listID = $2 ON CONFLICT DO NOTHING", tableName)
+	if _, err := tx.Exec(ctx, historyQuery, tenantId, list.ListId); err != nil {
+		return fmt.Errorf("error keeping list version in the history: %w", err)
+	}
 
-	updateQuery := fmt.Sprintf("UPDATE %s%s SET list = $1 WHERE listID = $2", TablePrefix, tenantId)
-	_, err = tx.Exec(ctx, updateQuery, specifiedList.List, specifiedList.ListId)
-	if err != nil {
+	updateQuery := fmt.Sprintf("UPDATE %s SET list = $1, version = version + 1, lastupdate = now() WHERE listID = $2", tableName)
+	if _, err := tx.Exec(ctx, updateQuery, list.List, list.ListId); err != nil {
 		return fmt.Errorf("error updating list in the database: %w", err)
 	}
 
-	err = tx.Commit(ctx)
-	if err != nil {
-		return fmt.Errorf("error commiting transaction: %w", err)
+	return nil
+}
+
+// recordStatusChange appends the change to the audit log.
+func recordStatusChange(ctx context.Context, tx pgx.Tx, change entity.StatusChange) error {
+	const insertQuery = "INSERT INTO status_audit (tenantid, listid, idx, oldstatus, newstatus, actor, reason, comment, requestid) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+	if _, err := tx.Exec(ctx, insertQuery, change.TenantId, change.ListId, change.Index, change.OldStatus, change.NewStatus, change.Actor, change.Reason, change.Comment, change.RequestId); err != nil {
+		return fmt.Errorf("error recording status change: %w", err)
 	}
 
 	return nil
 }
 
+// GetStatusChanges returns a page of the audit log of the tenant which matches the filter, newest first, and the number of matching entries.
+func (pc *postgresConnection) GetStatusChanges(ctx context.Context, tenantId string, filter entity.StatusChangeFilter, offset int, limit int) ([]entity.StatusChange, int, error) {
+	conditions := []string{"tenantid = $1"}
+	args := []interface{}{tenantId}
+
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ListId != nil {
+		addCondition("listid = $%d", *filter.ListId)
+	}
+	if filter.Index != nil {
+		addCondition("idx = $%d", *filter.Index)
+	}
+	if filter.Actor != "" {
+		addCondition("actor = $%d", filter.Actor)
+	}
+	if !filter.From.IsZero() {
+		addCondition("changedat >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("changedat < $%d", filter.To)
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := pc.conn.QueryRow(ctx, "SELECT count(*) FROM status_audit WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting status changes: %w", err)
+	}
+
+	selectQuery := fmt.Sprintf("SELECT id, tenantid, listid, idx, oldstatus, newstatus, actor, reason, comment, requestid, changedat FROM status_audit WHERE %s ORDER BY changedat DESC, id DESC OFFSET $%d LIMIT $%d", where, len(args)+1, len(args)+2)
+	rows, err := pc.conn.Query(ctx, selectQuery, append(args, offset, limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error selecting status changes: %w", err)
+	}
+
+	changes, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.StatusChange])
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading status changes: %w", err)
+	}
+
+	return changes, total, nil
+}
+
+func (pc *postgresConnection) GetLastStatusChange(ctx context.Context, tenantId string, listId int, index int, at time.Time) (*time.Time, error) {
+	// max over the prefix of status_audit_entry is answered from the index
+	selectQuery := "SELECT max(changedat) FROM status_audit WHERE tenantid = $1 AND listid = $2 AND idx = $3"
+	args := []interface{}{tenantId, listId, index}
+	if !at.IsZero() {
+		selectQuery += " AND changedat <= $4"
+		args = append(args, at)
+	}
+
+	var changedAt *time.Time
+	if err := pc.conn.QueryRow(ctx, selectQuery, args...).Scan(&changedAt); err != nil {
+		return nil, fmt.Errorf("error selecting last status change: %w", err)
+	}
+
+	return changedAt, nil
+}
+
 func (pc *postgresConnection) CacheList(ctx context.Context, cacheId string, list []byte) error {
 	tx, err := pc.conn.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:       pgx.ReadCommitted,
@@ -307,19 +1317,21 @@ func (pc *postgresConnection) CreateTableForTenantIdIfNotExists(ctx context.Cont
 	}
 
 	if !exists {
-		createTableQuery := fmt.Sprintf("CREATE TABLE %s (listID SERIAL PRIMARY KEY, list BYTEA, free INT)", tableName)
+		createTableQuery := fmt.Sprintf("CREATE TABLE %s (listID SERIAL PRIMARY KEY, list BYTEA, free INT, bits INT NOT NULL DEFAULT 1, allocated BYTEA, allocationmode TEXT NOT NULL DEFAULT 'sequential', purpose TEXT NOT NULL DEFAULT 'revocation', version BIGINT NOT NULL DEFAULT 1, lastupdate TIMESTAMPTZ NOT NULL DEFAULT now())", tableName)
 		_, err = tx.Exec(ctx, createTableQuery)
 		if err != nil {
 			return fmt.Errorf("could not create new table for tenantID: %w", err)
 		}
 
-		newList := entity.NewList(pc.listSizeInBytes)
+		newList := entity.NewListWithOptions(pc.listSizeInBytes, pc.defaultOptions)
 
-		insertQuery := fmt.Sprintf("INSERT INTO %s (list, free) VALUES ($1, $2)", tableName)
-		_, err = tx.Exec(ctx, insertQuery, newList.List, newList.Free)
+		insertQuery := fmt.Sprintf("INSERT INTO %s (list, free, bits, allocated, allocationmode, purpose) VALUES ($1, $2, $3, $4, $5, $6)", tableName)
+		_, err = tx.Exec(ctx, insertQuery, newList.List, newList.Free, newList.Bits, newList.Allocated, newList.AllocationMode, newList.Purpose)
 		if err != nil {
 			return fmt.Errorf("error inserting new list into the database: %w", err)
 		}
+	} else if err = migrateTenantTable(ctx, tx, tableName); err != nil {
+		return err
 	}
 
 	err = tx.Commit(ctx)
@@ -334,6 +1346,70 @@ func (pc *postgresConnection) Close() {
 	pc.conn.Close()
 }
 
+// migrateTenantTables migrates all tenant tables once at startup, so that reads of tenants which did not create entries
+// since the upgrade see the current columns. The tables of CacheList share the prefix, only tenant tables have the column free.
+func migrateTenantTables(ctx context.Context, conn *pgxpool.Pool) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	const tenantTablesQuery = `SELECT t.table_name FROM information_schema.tables t
+		WHERE t.table_schema = current_schema() AND t.table_name LIKE $1
+		AND EXISTS (SELECT 1 FROM information_schema.columns c WHERE c.table_schema = t.table_schema AND c.table_name = t.table_name AND c.column_name = 'free')`
+	rows, err := tx.Query(ctx, tenantTablesQuery, strings.ReplaceAll(TablePrefix, "_", `\_`)+"%")
+	if err != nil {
+		return fmt.Errorf("could not query tenant tables: %w", err)
+	}
+
+	tableNames, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("could not collect tenant tables: %w", err)
+	}
+
+	for _, tableName := range tableNames {
+		if err := migrateTenantTable(ctx, tx, pgx.Identifier{tableName}.Sanitize()); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error commiting transaction: %w", err)
+	}
+
+	return nil
+}
+
+// migrateTenantTable adds the columns introduced after the first release to tenant tables created by older versions.
+func migrateTenantTable(ctx context.Context, tx pgx.Tx, tableName string) error {
+	migrations := []string{
+		"ALTER TABLE %s ADD COLUMN IF NOT EXISTS bits INT NOT NULL DEFAULT 1",
+		"ALTER TABLE %s ADD COLUMN IF NOT EXISTS allocated BYTEA",
+		"ALTER TABLE %s ADD COLUMN IF NOT EXISTS allocationmode TEXT NOT NULL DEFAULT 'sequential'",
+		"ALTER TABLE %s ADD COLUMN IF NOT EXISTS purpose TEXT NOT NULL DEFAULT 'revocation'",
+		"ALTER TABLE %s ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1",
+		"ALTER TABLE %s ADD COLUMN IF NOT EXISTS lastupdate TIMESTAMPTZ NOT NULL DEFAULT now()",
+	}
+
+	for _, migration := range migrations {
+		if _, err := tx.Exec(ctx, fmt.Sprintf(migration, tableName)); err != nil {
+			return fmt.Errorf("could not migrate table for tenantID: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// listError reports lists of tenants without table as not found.
+func listError(err error, listId int) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == undefinedTable {
+		return fmt.Errorf("%w: listId %d", entity.ErrListNotFound, listId)
+	}
+	return err
+}
+
 func createTableName(tenantId string) (string, error) {
 	tableName := TablePrefix + tenantId
 	isValid, err := regexp.Match("^[a-zA-Z0-9_]+$", []byte(tableName))
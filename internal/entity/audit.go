@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+var ErrInvalidReason = fmt.Errorf("reason must be one of unspecified, keyCompromise, caCompromise, affiliationChanged, superseded, cessationOfOperation, certificateHold or privilegeWithdrawn")
+
+// Reason codes of status changes, named after the CRL reason codes of RFC 5280.
+const (
+	ReasonUnspecified          = "unspecified"
+	ReasonKeyCompromise        = "keyCompromise"
+	ReasonCaCompromise         = "caCompromise"
+	ReasonAffiliationChanged   = "affiliationChanged"
+	ReasonSuperseded           = "superseded"
+	ReasonCessationOfOperation = "cessationOfOperation"
+	ReasonCertificateHold      = "certificateHold"
+	ReasonPrivilegeWithdrawn   = "privilegeWithdrawn"
+)
+
+// ChangeReason describes who changes the status of entries and why.
+type ChangeReason struct {
+	Actor   string `json:"actor"`
+	Reason  string `json:"reason"`
+	Comment string `json:"comment"`
+	// RequestId correlates the change with the request which caused it.
+	RequestId string `json:"requestId"`
+}
+
+// Validate checks the reason code. An empty reason is unspecified.
+func (r *ChangeReason) Validate() error {
+	switch r.Reason {
+	case "":
+		r.Reason = ReasonUnspecified
+	case ReasonUnspecified, ReasonKeyCompromise, ReasonCaCompromise, ReasonAffiliationChanged, ReasonSuperseded, ReasonCessationOfOperation, ReasonCertificateHold, ReasonPrivilegeWithdrawn:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidReason, r.Reason)
+	}
+	return nil
+}
+
+// StatusChange is an entry of the audit log which records every change of the status of an entry.
+type StatusChange struct {
+	Id        int64     `json:"id" db:"id"`
+	TenantId  string    `json:"tenantId" db:"tenantid"`
+	ListId    int       `json:"listId" db:"listid"`
+	Index     int       `json:"index" db:"idx"`
+	OldStatus uint8     `json:"oldStatus" db:"oldstatus"`
+	NewStatus uint8     `json:"newStatus" db:"newstatus"`
+	Actor     string    `json:"actor" db:"actor"`
+	Reason    string    `json:"reason" db:"reason"`
+	Comment   string    `json:"comment" db:"comment"`
+	RequestId string    `json:"requestId" db:"requestid"`
+	Timestamp time.Time `json:"timestamp" db:"changedat"`
+}
+
+// NewStatusChange records that the entry changed from oldStatus to newStatus for reason.
+func NewStatusChange(tenantId string, entry Entry, oldStatus uint8, newStatus uint8, reason ChangeReason) StatusChange {
+	return StatusChange{
+		TenantId:  tenantId,
+		ListId:    entry.ListId,
+		Index:     entry.Index,
+		OldStatus: oldStatus,
+		NewStatus: newStatus,
+		Actor:     reason.Actor,
+		Reason:    reason.Reason,
+		Comment:   reason.Comment,
+		RequestId: reason.RequestId,
+	}
+}
+
+// StatusChangeFilter selects entries of the audit log. Zero values do not filter.
+type StatusChangeFilter struct {
+	ListId *int
+	Index  *int
+	Actor  string
+	From   time.Time
+	To     time.Time
+}
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateChangeReason(t *testing.T) {
+	reason := ChangeReason{}
+	require.NoError(t, reason.Validate())
+	require.Equal(t, ReasonUnspecified, reason.Reason)
+
+	reason = ChangeReason{Reason: ReasonKeyCompromise}
+	require.NoError(t, reason.Validate())
+
+	reason = ChangeReason{Reason: "bored"}
+	require.ErrorIs(t, reason.Validate(), ErrInvalidReason)
+}
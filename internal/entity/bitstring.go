@@ -0,0 +1,42 @@
+package entity
+
+// MinBitstringSize is the smallest uncompressed bitstring in bytes which the Bitstring Status List allows, smaller lists are padded with unused entries.
+const MinBitstringSize = 16 * 1024
+
+// PurposeMessage is the status purpose of Bitstring Status Lists whose entries have more than one bit.
+const PurposeMessage = "message"
+
+// Bitstring serializes the list as defined by the Bitstring Status List. Index 0 is the left-most, most significant bit and entries of several bits are stored big-endian within each byte.
+func (b *List) Bitstring() []byte {
+	size := len(b.List)
+	if size < MinBitstringSize {
+		size = MinBitstringSize
+	}
+
+	bitstring := make([]byte, size)
+	copy(bitstring, reorderEntries(b.List, b.bits()))
+	return bitstring
+}
+
+// DecodeBitstring converts the bitstring of a Bitstring Status List with entries of bits into the order of List, which starts at the least significant bit of each byte.
+func DecodeBitstring(bitstring []byte, bits int) ([]byte, error) {
+	if !ValidBits(bits) {
+		return nil, ErrInvalidBits
+	}
+	return reorderEntries(bitstring, bits), nil
+}
+
+// reorderEntries reverses the order of the entries within every byte. Applied twice it returns the original bytes.
+func reorderEntries(data []byte, bits int) []byte {
+	perByte := 8 / bits
+	mask := uint8(1<<bits - 1)
+
+	reordered := make([]byte, len(data))
+	for i, value := range data {
+		for k := 0; k < perByte; k++ {
+			entry := (value >> (k * bits)) & mask
+			reordered[i] |= entry << ((perByte - 1 - k) * bits)
+		}
+	}
+	return reordered
+}
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitstringOrder(t *testing.T) {
+	list := NewListWithOptions(2, ListOptions{Bits: 1, AllocationMode: AllocationSequential, Purpose: PurposeRevocation})
+	_, err := list.AllocateIndices(9)
+	require.NoError(t, err)
+	require.NoError(t, list.RevokeAtIndex(0))
+	require.NoError(t, list.RevokeAtIndex(8))
+
+	// index 0 is the left-most bit of the first byte
+	bitstring := list.Bitstring()
+	require.Len(t, bitstring, MinBitstringSize)
+	require.Equal(t, []byte{0x80, 0x80}, bitstring[:2])
+
+	decoded, err := DecodeBitstring(bitstring, 1)
+	require.NoError(t, err)
+	require.Equal(t, list.List, decoded[:2])
+}
+
+func TestBitstringOrderWithMultipleBits(t *testing.T) {
+	list := NewListWithOptions(1, ListOptions{Bits: 2, AllocationMode: AllocationSequential, Purpose: PurposeRevocation})
+	_, err := list.AllocateIndices(4)
+	require.NoError(t, err)
+	require.NoError(t, list.RevokeAtIndex(0))
+	require.NoError(t, list.SuspendAtIndex(3))
+
+	// 01 00 00 10, the first entry in the most significant bits
+	require.Equal(t, byte(0x42), list.Bitstring()[0])
+
+	decoded, err := DecodeBitstring([]byte{0x42}, 2)
+	require.NoError(t, err)
+	require.Equal(t, list.List, decoded)
+
+	_, err = DecodeBitstring([]byte{0x42}, 3)
+	require.ErrorIs(t, err, ErrInvalidBits)
+}
+
+func TestDecodeSpecificationExample(t *testing.T) {
+	// encodedList of the BitstringStatusListCredential example of the W3C Recommendation
+	const encodedList = "uH4sIAAAAAAAAA-3BMQEAAADCoPVPbQwfoAAAAAAAAAAAAAAAAAAAAIC3AYbSVKsAQAAA"
+
+	compressed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(encodedList, "u"))
+	require.NoError(t, err)
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	bitstring, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	decoded, err := DecodeBitstring(bitstring, 1)
+	require.NoError(t, err)
+	list := &List{List: decoded, Bits: 1, Purpose: PurposeRevocation}
+	require.Equal(t, MinBitstringSize*8, list.Size())
+
+	for _, index := range []int{0, 7, 94567, list.Size() - 1} {
+		revoked, err := list.IsRevoked(index)
+		require.NoError(t, err)
+		require.False(t, revoked)
+	}
+
+	// the Recommendation counts the bits of every byte from the left
+	bitstring[94567/8] |= 0x80 >> (94567 % 8)
+	decoded, err = DecodeBitstring(bitstring, 1)
+	require.NoError(t, err)
+	list.List = decoded
+	revoked, err := list.IsRevoked(94567)
+	require.NoError(t, err)
+	require.True(t, revoked)
+	revoked, err = list.IsRevoked(94560)
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
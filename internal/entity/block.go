@@ -1,52 +1,488 @@
 package entity
 
-import "fmt"
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"time"
+)
 
 var ErrFullyAllocated = fmt.Errorf("list is already fully allocated")
+var ErrInvalidBits = fmt.Errorf("status size must be one of 1, 2, 4 or 8 bits")
+var ErrInvalidStatus = fmt.Errorf("status value does not fit into the status size of the list")
+var ErrInvalidAllocationMode = fmt.Errorf("allocation mode must be sequential or random")
+var ErrInvalidPurpose = fmt.Errorf("status purpose must be revocation or suspension")
+var ErrRevocationNotSupported = fmt.Errorf("list does not support revocation")
+var ErrSuspensionNotSupported = fmt.Errorf("list does not support suspension")
+var ErrAlreadyRevoked = fmt.Errorf("entry is already revoked")
+var ErrIndexOutOfRange = fmt.Errorf("index is out of the range of the list")
+var ErrNotAllocated = fmt.Errorf("index is not allocated")
+var ErrListNotFound = fmt.Errorf("list not found")
+var ErrNotReleasable = fmt.Errorf("entry changed its status and can not be released")
+
+// DefaultBits is the status size used for lists which do not define one.
+const DefaultBits = 1
+
+// Status values as registered by the IETF Token Status List. Lists with a status size of one bit can only hold StatusValid and StatusInvalid.
+const (
+	StatusValid     uint8 = 0x00
+	StatusInvalid   uint8 = 0x01
+	StatusSuspended uint8 = 0x02
+)
+
+// Allocation modes of a list. Sequential lists hand out indices in order, random lists pick a uniformly random unused index for herd privacy.
+const (
+	AllocationSequential = "sequential"
+	AllocationRandom     = "random"
+)
+
+// Status purposes of a list. A single bit list either revokes or suspends its entries, lists with more bits support both as defined by the Token Status List.
+const (
+	PurposeRevocation = "revocation"
+	PurposeSuspension = "suspension"
+)
+
+// ListOptions describe the list in which an entry is allocated. Entries are only allocated in lists with matching options.
+type ListOptions struct {
+	Bits           int
+	AllocationMode string
+	Purpose        string
+}
 
 type List struct {
-	ListId int
-	List   []byte
-	Free   int
+	ListId         int
+	List           []byte
+	Free           int
+	Bits           int
+	Allocated      []byte
+	AllocationMode string
+	Purpose        string
+	// Version is increased with every status change of the list
+	Version    int64
+	LastUpdate time.Time
+	// nextFree is the first byte of the allocation bitmap which may have an unallocated index, all bytes before are full
+	nextFree int
 }
 
-func NewList(listSizeInBytes int) *List {
-	listBitSize := listSizeInBytes * 8
+func NewList(listSizeInBytes int, bits int) *List {
 	newBinaryList := make([]byte, listSizeInBytes)
 
 	for i := range newBinaryList {
 		newBinaryList[i] = 0
 	}
 
-	return &List{
-		ListId: 0,
-		List:   newBinaryList,
-		Free:   listBitSize,
+	list := &List{
+		ListId:         0,
+		List:           newBinaryList,
+		Bits:           bits,
+		AllocationMode: AllocationSequential,
+		Purpose:        PurposeRevocation,
+	}
+	list.Free = list.Size()
+	list.Allocated = make([]byte, (list.Size()+7)/8)
+
+	return list
+}
+
+// NewListWithOptions creates a list with the status size and allocation mode of options.
+func NewListWithOptions(listSizeInBytes int, options ListOptions) *List {
+	list := NewList(listSizeInBytes, options.Bits)
+	list.AllocationMode = options.AllocationMode
+	list.Purpose = options.Purpose
+
+	return list
+}
+
+// ValidBits reports whether bits is a status size supported by the status list specifications.
+func ValidBits(bits int) bool {
+	switch bits {
+	case 1, 2, 4, 8:
+		return true
+	}
+	return false
+}
+
+// ValidAllocationMode reports whether mode is a supported allocation mode.
+func ValidAllocationMode(mode string) bool {
+	return mode == AllocationSequential || mode == AllocationRandom
+}
+
+// ValidPurpose reports whether purpose is a supported status purpose.
+func ValidPurpose(purpose string) bool {
+	return purpose == PurposeRevocation || purpose == PurposeSuspension
+}
+
+// Validate checks that the options describe a list the service can create.
+func (o ListOptions) Validate() error {
+	if !ValidBits(o.Bits) {
+		return ErrInvalidBits
+	}
+	if !ValidAllocationMode(o.AllocationMode) {
+		return ErrInvalidAllocationMode
+	}
+	if !ValidPurpose(o.Purpose) {
+		return ErrInvalidPurpose
+	}
+	return nil
+}
+
+// Size returns the number of entries the list can hold with its status size.
+func (b *List) Size() int {
+	return len(b.List) * 8 / b.bits()
+}
+
+func (b *List) CheckBitAtIndex(index int) (bool, error) {
+	if err := b.checkIndex(index); err != nil {
+		return false, err
+	}
+
+	return b.status(index) == StatusInvalid, nil
+}
+
+// StatusAtIndex returns the status value of the entry at index. Entries are packed starting at the least significant bit of each byte.
+func (b *List) StatusAtIndex(index int) (uint8, error) {
+	if err := b.checkIndex(index); err != nil {
+		return 0, err
+	}
+
+	return b.status(index), nil
+}
+
+// SetStatusAtIndex overwrites the status value of the entry at index. Revocation is final, so revoked entries keep their status.
+func (b *List) SetStatusAtIndex(index int, status uint8) error {
+	if err := b.checkAllocated(index); err != nil {
+		return err
+	}
+
+	_, _, mask := b.position(index)
+	if status > mask {
+		return ErrInvalidStatus
 	}
+
+	if b.revoked(index) && status != StatusInvalid {
+		return ErrAlreadyRevoked
+	}
+
+	b.setStatus(index, status)
+	return nil
+}
+
+func (b *List) RevokeAtIndex(index int) error {
+	if err := b.checkAllocated(index); err != nil {
+		return err
+	}
+
+	if !b.SupportsRevocation() {
+		return ErrRevocationNotSupported
+	}
+
+	b.setStatus(index, StatusInvalid)
+	return nil
+}
+
+// SuspendAtIndex marks the entry at index as suspended. Revoked entries can not be suspended anymore.
+func (b *List) SuspendAtIndex(index int) error {
+	if err := b.checkAllocated(index); err != nil {
+		return err
+	}
+
+	if !b.SupportsSuspension() {
+		return ErrSuspensionNotSupported
+	}
+
+	if b.bits() == 1 {
+		b.setStatus(index, StatusInvalid)
+		return nil
+	}
+
+	if b.status(index) == StatusInvalid {
+		return ErrAlreadyRevoked
+	}
+
+	b.setStatus(index, StatusSuspended)
+	return nil
+}
+
+// UnsuspendAtIndex clears the suspension of the entry at index. Entries which are not suspended stay untouched.
+func (b *List) UnsuspendAtIndex(index int) error {
+	if err := b.checkAllocated(index); err != nil {
+		return err
+	}
+
+	if !b.SupportsSuspension() {
+		return ErrSuspensionNotSupported
+	}
+
+	if b.suspended(index) {
+		b.setStatus(index, StatusValid)
+	}
+	return nil
+}
+
+// IsRevoked reports whether the entry at index is revoked.
+func (b *List) IsRevoked(index int) (bool, error) {
+	if err := b.checkIndex(index); err != nil {
+		return false, err
+	}
+
+	return b.revoked(index), nil
+}
+
+// IsSuspended reports whether the entry at index is suspended.
+func (b *List) IsSuspended(index int) (bool, error) {
+	if err := b.checkIndex(index); err != nil {
+		return false, err
+	}
+
+	return b.suspended(index), nil
+}
+
+func (b *List) revoked(index int) bool {
+	return b.SupportsRevocation() && b.status(index) == StatusInvalid
 }
 
-func (b *List) CheckBitAtIndex(index int) bool {
-	if len(b.List) == 0 {
+func (b *List) suspended(index int) bool {
+	if !b.SupportsSuspension() {
 		return false
 	}
-	byteIndex, bitIndex := index/8, index%8
 
-	return (b.List[byteIndex] & (1 << bitIndex)) != 0
+	if b.bits() == 1 {
+		return b.status(index) == StatusInvalid
+	}
+	return b.status(index) == StatusSuspended
+}
 
+// SupportsRevocation reports whether entries of the list can be revoked.
+func (b *List) SupportsRevocation() bool {
+	return b.Purpose != PurposeSuspension || b.bits() > 1
 }
 
-func (b *List) RevokeAtIndex(index int) {
-	byteIndex, bitIndex := index/8, index%8
-	b.List[byteIndex] |= (1 << bitIndex)
+// SupportsSuspension reports whether entries of the list can be suspended.
+func (b *List) SupportsSuspension() bool {
+	return b.Purpose == PurposeSuspension || b.bits() > 1
+}
+
+// AllocateIndex allocates an unused index according to the allocation mode of the list.
+func (b *List) AllocateIndex() (int, error) {
+	if b.AllocationMode == AllocationRandom {
+		return b.AllocateRandomFreeIndex()
+	}
+	return b.AllocateNextFreeIndex()
+}
+
+// AllocateIndices allocates up to count indices with the allocation mode of the list. It returns fewer indices if the list fills up.
+func (b *List) AllocateIndices(count int) ([]int, error) {
+	if count > b.Free {
+		count = b.Free
+	}
+
+	indices := make([]int, 0, count)
+	for len(indices) < count {
+		index, err := b.AllocateIndex()
+		if err != nil {
+			return nil, err
+		}
+		indices = append(indices, index)
+	}
+
+	return indices, nil
 }
 
-func (b *List) AllocateNextFreeIndex() (index int, err error) {
-	if b.Free > 0 {
-		index = (len(b.List) * 8) - b.Free
+// AllocateNextFreeIndex allocates the lowest unallocated index. Released indices are handed out again before the indices behind them.
+func (b *List) AllocateNextFreeIndex() (int, error) {
+	if b.Free <= 0 {
+		return 0, ErrFullyAllocated
+	}
+	b.ensureAllocated()
+
+	size := b.Size()
+	for ; b.nextFree < len(b.Allocated); b.nextFree++ {
+		allocated := b.Allocated[b.nextFree]
+		if allocated == 0xff {
+			continue
+		}
+
+		index := b.nextFree*8 + bits.TrailingZeros8(^allocated)
+		if index >= size {
+			break
+		}
+		b.markAllocated(index)
 		b.Free--
-	} else {
-		err = ErrFullyAllocated
+		return index, nil
+	}
+
+	return 0, ErrFullyAllocated
+}
+
+// ReleaseIndex returns an allocated index to the unallocated indices of the list. Entries whose status changed are in use and can not be released.
+func (b *List) ReleaseIndex(index int) error {
+	if err := b.checkAllocated(index); err != nil {
+		return err
+	}
+
+	if b.status(index) != StatusValid {
+		return fmt.Errorf("%w: %d", ErrNotReleasable, index)
+	}
+
+	b.Allocated[index/8] &^= 1 << (index % 8)
+	b.Free++
+	if index/8 < b.nextFree {
+		b.nextFree = index / 8
+	}
+	return nil
+}
+
+// AllocateRandomFreeIndex picks an index uniformly at random from all indices which are not allocated yet.
+func (b *List) AllocateRandomFreeIndex() (int, error) {
+	if b.Free <= 0 {
+		return 0, ErrFullyAllocated
+	}
+	b.ensureAllocated()
+
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(b.Free)))
+	if err != nil {
+		return 0, fmt.Errorf("error drawing random index: %w", err)
+	}
+
+	// find the n-th unallocated index
+	rank := int(n.Int64())
+	size := b.Size()
+	for byteIndex, allocated := range b.Allocated {
+		unallocated := 8 - bits.OnesCount8(allocated)
+		if byteIndex == len(b.Allocated)-1 && size%8 != 0 {
+			unallocated -= 8 - size%8
+		}
+		if rank >= unallocated {
+			rank -= unallocated
+			continue
+		}
+
+		for bitIndex := 0; bitIndex < 8; bitIndex++ {
+			if allocated&(1<<bitIndex) != 0 {
+				continue
+			}
+			if rank == 0 {
+				index := byteIndex*8 + bitIndex
+				b.markAllocated(index)
+				b.Free--
+				return index, nil
+			}
+			rank--
+		}
+	}
+
+	return 0, ErrFullyAllocated
+}
+
+// ListStatistics counts the entries of a list by their state.
+type ListStatistics struct {
+	Size      int `json:"size"`
+	Allocated int `json:"allocated"`
+	Free      int `json:"free"`
+	Revoked   int `json:"revoked"`
+	Suspended int `json:"suspended"`
+}
+
+// Add sums up the statistics of several lists.
+func (s *ListStatistics) Add(other ListStatistics) {
+	s.Size += other.Size
+	s.Allocated += other.Allocated
+	s.Free += other.Free
+	s.Revoked += other.Revoked
+	s.Suspended += other.Suspended
+}
+
+// Statistics counts the allocated, revoked and suspended entries of the list.
+func (b *List) Statistics() ListStatistics {
+	statistics := ListStatistics{
+		Size:      b.Size(),
+		Allocated: b.Size() - b.Free,
+		Free:      b.Free,
+	}
+
+	for index := 0; index < statistics.Size; index++ {
+		if b.revoked(index) {
+			statistics.Revoked++
+		} else if b.suspended(index) {
+			statistics.Suspended++
+		}
+	}
+
+	return statistics
+}
+
+// IsAllocated reports whether index was handed out by an allocation.
+func (b *List) IsAllocated(index int) (bool, error) {
+	if err := b.checkIndex(index); err != nil {
+		return false, err
+	}
+
+	return b.allocated(index), nil
+}
+
+func (b *List) allocated(index int) bool {
+	b.ensureAllocated()
+	return b.Allocated[index/8]&(1<<(index%8)) != 0
+}
+
+func (b *List) markAllocated(index int) {
+	b.Allocated[index/8] |= 1 << (index % 8)
+}
+
+// ensureAllocated builds the allocation bitmap for lists stored before the bitmap existed. Those lists were always allocated sequentially.
+func (b *List) ensureAllocated() {
+	if len(b.Allocated) != 0 {
+		return
+	}
+
+	b.Allocated = make([]byte, (b.Size()+7)/8)
+	for index := 0; index < b.Size()-b.Free; index++ {
+		b.markAllocated(index)
 	}
+}
+
+// checkIndex fails for indices outside of the list.
+func (b *List) checkIndex(index int) error {
+	if index < 0 || index >= b.Size() {
+		return fmt.Errorf("%w: %d not in [0, %d)", ErrIndexOutOfRange, index, b.Size())
+	}
+	return nil
+}
+
+// checkAllocated fails for indices which were never handed out, their status must not change.
+func (b *List) checkAllocated(index int) error {
+	if err := b.checkIndex(index); err != nil {
+		return err
+	}
+
+	if !b.allocated(index) {
+		return fmt.Errorf("%w: %d", ErrNotAllocated, index)
+	}
+	return nil
+}
 
-	return index, err
+func (b *List) status(index int) uint8 {
+	byteIndex, bitIndex, mask := b.position(index)
+
+	return (b.List[byteIndex] >> bitIndex) & mask
+}
+
+func (b *List) setStatus(index int, status uint8) {
+	byteIndex, bitIndex, mask := b.position(index)
+	b.List[byteIndex] = (b.List[byteIndex] &^ (mask << bitIndex)) | (status << bitIndex)
+}
+
+func (b *List) position(index int) (byteIndex int, bitIndex int, mask uint8) {
+	bits := b.bits()
+	offset := index * bits
+
+	return offset / 8, offset % 8, uint8(1<<bits - 1)
+}
+
+func (b *List) bits() int {
+	if b.Bits == 0 {
+		return DefaultBits
+	}
+	return b.Bits
 }
@@ -6,11 +6,35 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func requireStatus(t *testing.T, list *List, index int, expected uint8) {
+	status, err := list.StatusAtIndex(index)
+	require.NoError(t, err)
+	require.Equal(t, expected, status)
+}
+
+func requireAllocated(t *testing.T, list *List, index int, expected bool) {
+	allocated, err := list.IsAllocated(index)
+	require.NoError(t, err)
+	require.Equal(t, expected, allocated)
+}
+
+func requireRevoked(t *testing.T, list *List, index int, expected bool) {
+	revoked, err := list.IsRevoked(index)
+	require.NoError(t, err)
+	require.Equal(t, expected, revoked)
+}
+
+func requireSuspended(t *testing.T, list *List, index int, expected bool) {
+	suspended, err := list.IsSuspended(index)
+	require.NoError(t, err)
+	require.Equal(t, expected, suspended)
+}
+
 func TestNewListWithCorrectSizeAndFreeCount(t *testing.T) {
 	wantedByteSize := 100
 	wantedFreeSize := wantedByteSize * 8
 
-	newList := NewList(wantedByteSize)
+	newList := NewList(wantedByteSize, 1)
 
 	require.Equal(t, wantedByteSize, len(newList.List))
 	require.Equal(t, wantedFreeSize, newList.Free)
@@ -28,8 +52,10 @@ func TestRevokeIndexInList(t *testing.T) {
 		}
 	}
 
-	list := NewList(byteListSize)
-	list.RevokeAtIndex(7)
+	list := NewList(byteListSize, 1)
+	_, err := list.AllocateIndices(8)
+	require.NoError(t, err)
+	require.NoError(t, list.RevokeAtIndex(7))
 
 	require.Equal(t, wantedList, list.List)
 }
@@ -38,7 +64,7 @@ func TestGetNextFreeIndexAndReduceFreeCount(t *testing.T) {
 	byteListSize := 10
 	maxIndex := (byteListSize * 8) - 1
 
-	list := NewList(byteListSize)
+	list := NewList(byteListSize, 1)
 	for i := 0; i < maxIndex; i++ {
 		_, err := list.AllocateNextFreeIndex()
 		if err != nil {
@@ -61,7 +87,7 @@ func TestTryToAllocateNewIndexInFullyAllocatedList(t *testing.T) {
 	byteListSize := 10
 	maxIndex := (byteListSize * 8) - 1
 
-	list := NewList(byteListSize)
+	list := NewList(byteListSize, 1)
 	for i := 0; i <= maxIndex; i++ {
 		_, err := list.AllocateNextFreeIndex()
 		if err != nil {
@@ -75,19 +101,248 @@ func TestTryToAllocateNewIndexInFullyAllocatedList(t *testing.T) {
 
 func TestRevoked(t *testing.T) {
 	byteListSize := 2
-	list := NewList(byteListSize)
+	list := NewList(byteListSize, 1)
 	idx, _ := list.AllocateNextFreeIndex()
 
 	list.RevokeAtIndex(idx)
-	b := list.CheckBitAtIndex(idx)
+	b, err := list.CheckBitAtIndex(idx)
+	require.NoError(t, err)
 
 	if !b {
 		t.Error()
 	}
 
-	b = list.CheckBitAtIndex(2)
+	b, err = list.CheckBitAtIndex(2)
+	require.NoError(t, err)
 
 	if b {
 		t.Error()
 	}
 }
+
+func TestNewListWithMultipleBitsPerEntry(t *testing.T) {
+	byteListSize := 10
+
+	for _, bits := range []int{1, 2, 4, 8} {
+		list := NewList(byteListSize, bits)
+
+		require.Equal(t, byteListSize*8/bits, list.Size())
+		require.Equal(t, byteListSize*8/bits, list.Free)
+	}
+}
+
+func TestSetAndReadStatusWithMultipleBits(t *testing.T) {
+	list := NewList(2, 2)
+	_, err := list.AllocateIndices(list.Size())
+	require.NoError(t, err)
+
+	require.NoError(t, list.SetStatusAtIndex(0, StatusInvalid))
+	require.NoError(t, list.SetStatusAtIndex(1, StatusSuspended))
+	require.NoError(t, list.SetStatusAtIndex(4, 3))
+
+	require.Equal(t, []byte{0b00001001, 0b00000011}, list.List)
+	requireStatus(t, list, 0, StatusInvalid)
+	requireStatus(t, list, 1, StatusSuspended)
+	requireStatus(t, list, 2, StatusValid)
+	requireStatus(t, list, 4, 3)
+
+	revoked, err := list.CheckBitAtIndex(0)
+	require.NoError(t, err)
+	require.True(t, revoked)
+	revoked, err = list.CheckBitAtIndex(1)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	require.NoError(t, list.SetStatusAtIndex(1, StatusValid))
+	requireStatus(t, list, 1, StatusValid)
+	requireStatus(t, list, 0, StatusInvalid)
+
+	require.ErrorIs(t, list.SetStatusAtIndex(2, 4), ErrInvalidStatus)
+	require.ErrorIs(t, list.SetStatusAtIndex(0, StatusValid), ErrAlreadyRevoked)
+	require.NoError(t, list.SetStatusAtIndex(0, StatusInvalid))
+}
+
+func TestAllocateNextFreeIndexWithMultipleBits(t *testing.T) {
+	list := NewList(1, 4)
+
+	first, err := list.AllocateNextFreeIndex()
+	require.NoError(t, err)
+	second, err := list.AllocateNextFreeIndex()
+	require.NoError(t, err)
+
+	require.Equal(t, 0, first)
+	require.Equal(t, 1, second)
+
+	_, err = list.AllocateNextFreeIndex()
+	require.ErrorIs(t, err, ErrFullyAllocated)
+}
+
+func TestAllocateRandomFreeIndexNeverRepeats(t *testing.T) {
+	byteListSize := 3
+
+	list := NewListWithOptions(byteListSize, ListOptions{Bits: 2, AllocationMode: AllocationRandom})
+	seen := make(map[int]bool)
+	for i := 0; i < list.Size(); i++ {
+		index, err := list.AllocateIndex()
+		require.NoError(t, err)
+		require.False(t, seen[index], "index %d allocated twice", index)
+		requireAllocated(t, list, index, true)
+		require.Less(t, index, list.Size())
+		seen[index] = true
+	}
+
+	require.Equal(t, 0, list.Free)
+	_, err := list.AllocateIndex()
+	require.ErrorIs(t, err, ErrFullyAllocated)
+}
+
+func TestAllocateIndicesUntilListIsFull(t *testing.T) {
+	list := NewList(2, 2)
+
+	indices, err := list.AllocateIndices(3)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2}, indices)
+
+	indices, err = list.AllocateIndices(10)
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 4, 5, 6, 7}, indices)
+	require.Equal(t, 0, list.Free)
+
+	indices, err = list.AllocateIndices(1)
+	require.NoError(t, err)
+	require.Empty(t, indices)
+}
+
+func TestAllocationBitmapOfListsWithoutBitmap(t *testing.T) {
+	list := &List{List: make([]byte, 1), Free: 5, Bits: 1}
+
+	requireAllocated(t, list, 2, true)
+	requireAllocated(t, list, 3, false)
+
+	list.AllocationMode = AllocationRandom
+	index, err := list.AllocateIndex()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, index, 3)
+}
+
+func TestSuspendAndUnsuspendSingleBitList(t *testing.T) {
+	list := NewListWithOptions(1, ListOptions{Bits: 1, AllocationMode: AllocationSequential, Purpose: PurposeSuspension})
+	_, err := list.AllocateIndices(list.Size())
+	require.NoError(t, err)
+
+	require.NoError(t, list.SuspendAtIndex(3))
+	requireSuspended(t, list, 3, true)
+	requireRevoked(t, list, 3, false)
+
+	require.NoError(t, list.UnsuspendAtIndex(3))
+	requireSuspended(t, list, 3, false)
+
+	require.ErrorIs(t, list.RevokeAtIndex(3), ErrRevocationNotSupported)
+}
+
+func TestSuspendInRevocationList(t *testing.T) {
+	list := NewList(1, 1)
+	_, err := list.AllocateIndex()
+	require.NoError(t, err)
+
+	require.ErrorIs(t, list.SuspendAtIndex(0), ErrSuspensionNotSupported)
+	require.ErrorIs(t, list.UnsuspendAtIndex(0), ErrSuspensionNotSupported)
+	requireSuspended(t, list, 0, false)
+}
+
+func TestSuspendAndRevokeMultiBitList(t *testing.T) {
+	list := NewList(1, 2)
+	_, err := list.AllocateIndices(2)
+	require.NoError(t, err)
+
+	require.NoError(t, list.SuspendAtIndex(1))
+	requireSuspended(t, list, 1, true)
+	requireStatus(t, list, 1, StatusSuspended)
+
+	require.NoError(t, list.RevokeAtIndex(1))
+	requireRevoked(t, list, 1, true)
+	requireSuspended(t, list, 1, false)
+
+	require.ErrorIs(t, list.SuspendAtIndex(1), ErrAlreadyRevoked)
+	require.NoError(t, list.UnsuspendAtIndex(1))
+	requireRevoked(t, list, 1, true)
+}
+
+func TestListStatistics(t *testing.T) {
+	list := NewListWithOptions(1, ListOptions{Bits: 2, AllocationMode: AllocationSequential, Purpose: PurposeRevocation})
+	_, err := list.AllocateIndices(3)
+	require.NoError(t, err)
+	require.NoError(t, list.RevokeAtIndex(0))
+	require.NoError(t, list.SuspendAtIndex(2))
+
+	statistics := list.Statistics()
+	require.Equal(t, ListStatistics{Size: 4, Allocated: 3, Free: 1, Revoked: 1, Suspended: 1}, statistics)
+
+	statistics.Add(NewList(1, 1).Statistics())
+	require.Equal(t, ListStatistics{Size: 12, Allocated: 3, Free: 9, Revoked: 1, Suspended: 1}, statistics)
+}
+
+func TestIndexOutOfRange(t *testing.T) {
+	list := NewList(1, 2)
+
+	for _, index := range []int{-1, 4, 999999999} {
+		_, err := list.StatusAtIndex(index)
+		require.ErrorIs(t, err, ErrIndexOutOfRange)
+		_, err = list.CheckBitAtIndex(index)
+		require.ErrorIs(t, err, ErrIndexOutOfRange)
+		_, err = list.IsAllocated(index)
+		require.ErrorIs(t, err, ErrIndexOutOfRange)
+		require.ErrorIs(t, list.RevokeAtIndex(index), ErrIndexOutOfRange)
+		require.ErrorIs(t, list.SuspendAtIndex(index), ErrIndexOutOfRange)
+		require.ErrorIs(t, list.SetStatusAtIndex(index, StatusValid), ErrIndexOutOfRange)
+	}
+}
+
+func TestChangeStatusOfUnallocatedIndex(t *testing.T) {
+	list := NewListWithOptions(1, ListOptions{Bits: 2, AllocationMode: AllocationRandom, Purpose: PurposeRevocation})
+
+	require.ErrorIs(t, list.RevokeAtIndex(0), ErrNotAllocated)
+	require.ErrorIs(t, list.SuspendAtIndex(0), ErrNotAllocated)
+	require.ErrorIs(t, list.UnsuspendAtIndex(0), ErrNotAllocated)
+	require.ErrorIs(t, list.SetStatusAtIndex(0, StatusInvalid), ErrNotAllocated)
+	require.Equal(t, []byte{0}, list.List)
+}
+
+func TestReleaseIndex(t *testing.T) {
+	list := NewListWithOptions(2, ListOptions{Bits: 1, AllocationMode: AllocationSequential, Purpose: PurposeRevocation})
+	indices, err := list.AllocateIndices(10)
+	require.NoError(t, err)
+	require.Equal(t, 6, list.Free)
+
+	require.NoError(t, list.ReleaseIndex(3))
+	require.NoError(t, list.ReleaseIndex(8))
+	require.Equal(t, 8, list.Free)
+	allocated, err := list.IsAllocated(3)
+	require.NoError(t, err)
+	require.False(t, allocated)
+
+	// released indices are handed out again first
+	indices, err = list.AllocateIndices(3)
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 8, 10}, indices)
+
+	require.NoError(t, list.RevokeAtIndex(4))
+	require.ErrorIs(t, list.ReleaseIndex(4), ErrNotReleasable)
+	require.ErrorIs(t, list.ReleaseIndex(11), ErrNotAllocated)
+	require.ErrorIs(t, list.ReleaseIndex(16), ErrIndexOutOfRange)
+}
+
+func TestReleaseIndexOfLegacyList(t *testing.T) {
+	// lists stored before the allocation bitmap existed were allocated sequentially
+	list := NewList(1, 1)
+	list.Free = 5
+	list.Allocated = nil
+
+	require.NoError(t, list.ReleaseIndex(1))
+	index, err := list.AllocateNextFreeIndex()
+	require.NoError(t, err)
+	require.Equal(t, 1, index)
+	index, err = list.AllocateNextFreeIndex()
+	require.NoError(t, err)
+	require.Equal(t, 3, index)
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+var ErrCredentialNotFound = fmt.Errorf("credential reference not found")
+var ErrCredentialRefExists = fmt.Errorf("credential reference is already registered")
+var ErrInvalidCredentialRef = fmt.Errorf("credential reference must not be empty or longer than 1024 characters")
+
+// maxCredentialRefLength bounds the references which the registry stores.
+const maxCredentialRefLength = 1024
+
+// CredentialEntry maps the reference of a credential, e.g. the id of a VC or the jti of a JWT, to its entry.
+type CredentialEntry struct {
+	Ref       string    `json:"credentialRef" db:"ref"`
+	ListId    int       `json:"listId" db:"listid"`
+	Index     int       `json:"index" db:"idx"`
+	CreatedAt time.Time `json:"createdAt" db:"createdat"`
+}
+
+// ValidateCredentialRef checks that ref can be registered.
+func ValidateCredentialRef(ref string) error {
+	if ref == "" || len(ref) > maxCredentialRefLength {
+		return ErrInvalidCredentialRef
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCredentialRef(t *testing.T) {
+	require.NoError(t, ValidateCredentialRef("urn:uuid:3978344f-8596-4c3a-a978-8fcaba3903c5"))
+	require.NoError(t, ValidateCredentialRef(strings.Repeat("a", maxCredentialRefLength)))
+	require.ErrorIs(t, ValidateCredentialRef(""), ErrInvalidCredentialRef)
+	require.ErrorIs(t, ValidateCredentialRef(strings.Repeat("a", maxCredentialRefLength+1)), ErrInvalidCredentialRef)
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+var ErrIdempotencyKeyReused = fmt.Errorf("idempotency key was already used for another request")
+var ErrInvalidIdempotencyKey = fmt.Errorf("idempotency key must not be empty or longer than 255 characters")
+
+// maxIdempotencyKeyLength bounds the keys which are stored with the allocations.
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyKey deduplicates repeated creation requests of a tenant. Repetitions within Window return the entries of the first request.
+type IdempotencyKey struct {
+	Key string
+	// Fingerprint identifies the content of the request, a repetition with other content fails
+	Fingerprint string
+	Window      time.Duration
+}
+
+// Validate checks that the key can be stored.
+func (k *IdempotencyKey) Validate() error {
+	if k.Key == "" || len(k.Key) > maxIdempotencyKeyLength {
+		return fmt.Errorf("%w: %q", ErrInvalidIdempotencyKey, k.Key)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateIdempotencyKey(t *testing.T) {
+	key := IdempotencyKey{Key: "8e03978e-40d5-43e8-bc93-6894a57f9324"}
+	require.NoError(t, key.Validate())
+
+	key.Key = ""
+	require.ErrorIs(t, key.Validate(), ErrInvalidIdempotencyKey)
+
+	key.Key = strings.Repeat("k", maxIdempotencyKeyLength+1)
+	require.ErrorIs(t, key.Validate(), ErrInvalidIdempotencyKey)
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+var ErrReservationNotFound = fmt.Errorf("reservation not found")
+var ErrReservationExpired = fmt.Errorf("reservation is expired")
+
+// Reservation holds an allocated index for an issuance until it is committed, released or expires.
+type Reservation struct {
+	Id        string    `json:"reservationId" db:"id"`
+	TenantId  string    `json:"tenantId" db:"tenantid"`
+	ListId    int       `json:"listId" db:"listid"`
+	Index     int       `json:"index" db:"idx"`
+	ExpiresAt time.Time `json:"expiresAt" db:"expiresat"`
+}
+
+// Expired reports whether the reservation can no longer be committed at now.
+func (r *Reservation) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
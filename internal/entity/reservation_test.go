@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservationExpired(t *testing.T) {
+	now := time.Now()
+	reservation := Reservation{ExpiresAt: now.Add(time.Minute)}
+
+	require.False(t, reservation.Expired(now))
+	require.True(t, reservation.Expired(now.Add(time.Minute)))
+}
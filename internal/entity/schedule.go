@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+var ErrInvalidAction = fmt.Errorf("scheduled action must be revoke or suspend")
+
+// Actions which the scheduler applies to entries.
+const (
+	ActionRevoke  = "revoke"
+	ActionSuspend = "suspend"
+)
+
+// ActorScheduler is the actor of the status changes which the scheduler applies.
+const ActorScheduler = "scheduler"
+
+// ScheduledAction is an action which is planned for every entry of an allocation.
+type ScheduledAction struct {
+	Action string
+	Reason string
+	DueAt  time.Time
+}
+
+// ScheduledChange is a status change of an entry which the scheduler applies once it is due.
+type ScheduledChange struct {
+	Id        int64      `json:"id" db:"id"`
+	TenantId  string     `json:"tenantId" db:"tenantid"`
+	ListId    int        `json:"listId" db:"listid"`
+	Index     int        `json:"index" db:"idx"`
+	Action    string     `json:"action" db:"action"`
+	Reason    string     `json:"reason" db:"reason"`
+	DueAt     time.Time  `json:"dueAt" db:"dueat"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty" db:"appliedat"`
+	Error     string     `json:"error,omitempty" db:"error"`
+}
+
+// Apply changes the status of the entry in the list.
+func (c *ScheduledChange) Apply(list *List) error {
+	switch c.Action {
+	case ActionRevoke:
+		return list.RevokeAtIndex(c.Index)
+	case ActionSuspend:
+		return list.SuspendAtIndex(c.Index)
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidAction, c.Action)
+}
+
+// ChangeReason returns the reason under which the change is recorded in the audit log.
+func (c *ScheduledChange) ChangeReason() ChangeReason {
+	return ChangeReason{
+		Actor:     ActorScheduler,
+		Reason:    c.Reason,
+		Comment:   fmt.Sprintf("scheduled %s due at %s", c.Action, c.DueAt.UTC().Format(time.RFC3339)),
+		RequestId: fmt.Sprintf("schedule-%d", c.Id),
+	}
+}
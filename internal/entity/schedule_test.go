@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyScheduledChange(t *testing.T) {
+	list := NewListWithOptions(1, ListOptions{Bits: 2, AllocationMode: AllocationSequential, Purpose: PurposeRevocation})
+	_, err := list.AllocateIndices(3)
+	require.NoError(t, err)
+
+	revoke := ScheduledChange{Index: 1, Action: ActionRevoke}
+	require.NoError(t, revoke.Apply(list))
+	requireRevoked(t, list, 1, true)
+
+	suspend := ScheduledChange{Index: 2, Action: ActionSuspend}
+	require.NoError(t, suspend.Apply(list))
+	requireSuspended(t, list, 2, true)
+
+	suspendRevoked := ScheduledChange{Index: 1, Action: ActionSuspend}
+	require.ErrorIs(t, suspendRevoked.Apply(list), ErrAlreadyRevoked)
+
+	unknown := ScheduledChange{Index: 0, Action: "delete"}
+	require.ErrorIs(t, unknown.Apply(list), ErrInvalidAction)
+
+	reason := revoke.ChangeReason()
+	require.Equal(t, ActorScheduler, reason.Actor)
+}
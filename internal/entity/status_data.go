@@ -4,12 +4,30 @@ import "fmt"
 
 type StatusData struct {
 	Index     int    `json:"index"`
+	ListId    int    `json:"listId"`
 	StatusUrl string `json:"statusUrl"`
 }
 
 func NewStatusData(index int, listId int) *StatusData {
 	return &StatusData{
 		Index:     index,
+		ListId:    listId,
 		StatusUrl: "/status/" + fmt.Sprintf("%d", listId),
 	}
 }
+
+// Entry addresses an index of a list.
+type Entry struct {
+	ListId int `json:"listId"`
+	Index  int `json:"index"`
+}
+
+// Allocation describes what is stored together with allocated entries.
+type Allocation struct {
+	// Actions are scheduled for every entry
+	Actions []ScheduledAction
+	// CredentialRefs are registered for the entries in allocation order, either none or one per entry
+	CredentialRefs []string
+	// Idempotency returns the entries of an earlier request with the same key instead of allocating new ones
+	Idempotency *IdempotencyKey
+}
@@ -0,0 +1,58 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ed25519"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// COSE values, see RFC 9052 and RFC 9053
+const (
+	coseHeaderAlg  = 1
+	coseAlgEdDSA   = -8
+	coseAlgES256   = -7
+	coseSign1Tag   = 18
+	coseSign1Label = "Signature1"
+)
+
+func coseAlgorithm(key crypto.Signer) int {
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		return coseAlgEdDSA
+	}
+	return coseAlgES256
+}
+
+// signCoseSign1 builds a tagged COSE_Sign1 structure as defined by RFC 9052 section 4.2.
+func signCoseSign1(key crypto.Signer, header map[int]interface{}, payload []byte) ([]byte, error) {
+	encoder, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		return nil, err
+	}
+
+	protectedHeader := make(map[int]interface{}, len(header)+1)
+	for label, value := range header {
+		protectedHeader[label] = value
+	}
+	protectedHeader[coseHeaderAlg] = coseAlgorithm(key)
+
+	protected, err := encoder.Marshal(protectedHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	toBeSigned, err := encoder.Marshal([]interface{}{coseSign1Label, protected, []byte{}, payload})
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signRaw(key, toBeSigned)
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.Marshal(cbor.Tag{
+		Number:  coseSign1Tag,
+		Content: []interface{}{protected, map[int]interface{}{}, payload, signature},
+	})
+}
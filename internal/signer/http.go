@@ -0,0 +1,135 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Http adds proofs over the http interface of the signer service. It does not sign tokens.
+type Http struct {
+	url     string
+	client  *http.Client
+	options Options
+}
+
+func NewHttp(url string, options Options) *Http {
+	return &Http{
+		url:     url,
+		client:  new(http.Client),
+		options: options,
+	}
+}
+
+func (h *Http) SignJwt(ctx context.Context, key KeyRef, header map[string]interface{}, claims map[string]interface{}) ([]byte, error) {
+	return nil, ErrUnsupported
+}
+
+func (h *Http) SignCwt(ctx context.Context, key KeyRef, header map[int]interface{}, claims []byte) ([]byte, error) {
+	return nil, ErrUnsupported
+}
+
+func (h *Http) AddProof(ctx context.Context, key KeyRef, credential map[string]interface{}) (map[string]interface{}, error) {
+	payload := make(map[string]interface{})
+
+	payload["namespace"] = key.Namespace
+	payload["group"] = key.Group
+	payload["key"] = key.Key
+	payload["credential"] = credential
+	p, err := json.Marshal(payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var r map[string]interface{}
+	err = retry(ctx, h.options, func(ctx context.Context) error {
+		respBody, err := h.post(ctx, "/credential/proof", p, nil)
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(respBody, &r)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (h *Http) VerifyProof(ctx context.Context, key KeyRef, credential []byte) error {
+	p, err := json.Marshal(map[string]interface{}{"credential": credential})
+
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Add("x-namespace", key.Namespace)
+	header.Add("x-group", key.Group)
+
+	var r struct {
+		Valid bool `json:"valid"`
+	}
+	err = retry(ctx, h.options, func(ctx context.Context) error {
+		respBody, err := h.post(ctx, "/credential/verify", p, header)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(respBody, &r); err != nil {
+			return fmt.Errorf("%w: %w", ErrRejected, err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if !r.Valid {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// post sends the body to the signer service and maps failures to ErrUnavailable and ErrRejected.
+func (h *Http) post(ctx context.Context, path string, body []byte, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url+path, bytes.NewBuffer(body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Add("Content-Type", "application/json")
+	rep, err := h.client.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	defer rep.Body.Close()
+
+	respBody, err := io.ReadAll(rep.Body)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	if rep.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: signer service call error. result was: %s %s", ErrUnavailable, rep.Status, string(respBody))
+	}
+
+	if rep.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: signer service call error. result was: %s %s", ErrRejected, rep.Status, string(respBody))
+	}
+
+	return respBody, nil
+}
@@ -0,0 +1,90 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpAddProof(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/credential/proof", r.URL.Path)
+
+		var payload map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		require.Equal(t, "transit", payload["namespace"])
+		require.Equal(t, "key", payload["key"])
+
+		credential := payload["credential"].(map[string]interface{})
+		credential["proof"] = map[string]interface{}{"type": "DataIntegrityProof"}
+		require.NoError(t, json.NewEncoder(w).Encode(credential))
+	}))
+	defer server.Close()
+
+	signer := NewHttp(server.URL, Options{})
+	secured, err := signer.AddProof(context.Background(), KeyRef{Namespace: "transit", Key: "key"}, map[string]interface{}{"issuer": "did:web:example.com"})
+	require.NoError(t, err)
+	require.Equal(t, "did:web:example.com", secured["issuer"])
+	require.Contains(t, secured, "proof")
+}
+
+func TestHttpVerifyProof(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/credential/verify", r.URL.Path)
+		require.Equal(t, "transit", r.Header.Get("x-namespace"))
+		require.Equal(t, "group", r.Header.Get("x-group"))
+
+		var payload struct {
+			Credential []byte `json:"credential"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		json.NewEncoder(w).Encode(map[string]bool{"valid": string(payload.Credential) == "valid"})
+	}))
+	defer server.Close()
+
+	signer := NewHttp(server.URL, Options{})
+	key := KeyRef{Namespace: "transit", Group: "group"}
+	require.NoError(t, signer.VerifyProof(context.Background(), key, []byte("valid")))
+	require.ErrorIs(t, signer.VerifyProof(context.Background(), key, []byte("forged")), ErrInvalidProof)
+}
+
+func TestHttpRetriesUnavailableSigner(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	_, err := NewHttp(server.URL, Options{Retries: 1}).AddProof(context.Background(), KeyRef{}, nil)
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.Equal(t, int32(2), calls.Load())
+
+	_, err = NewHttp(server.URL, Options{Retries: 1}).AddProof(context.Background(), KeyRef{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestHttpRejectedRequest(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := NewHttp(server.URL, Options{Retries: 2}).AddProof(context.Background(), KeyRef{}, nil)
+	require.ErrorIs(t, err, ErrRejected)
+	require.Equal(t, int32(1), calls.Load())
+
+	_, err = NewHttp(server.URL, Options{}).SignJwt(context.Background(), KeyRef{}, nil, nil)
+	require.ErrorIs(t, err, ErrUnsupported)
+}
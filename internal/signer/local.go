@@ -0,0 +1,167 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+)
+
+var validName = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+
+// Local signs in-process with Ed25519 or P-256 keys. The keys are stored per tenant as PEM (PKCS#8 or SEC 1) or JWK file under <keyDir>/<tenantId>/<key>.pem or <keyDir>/<tenantId>/<key>.jwk.
+type Local struct {
+	keyDir string
+	mutex  sync.RWMutex
+	keys   map[string]crypto.Signer
+}
+
+func NewLocal(keyDir string) *Local {
+	return &Local{
+		keyDir: keyDir,
+		keys:   make(map[string]crypto.Signer),
+	}
+}
+
+func (l *Local) SignJwt(ctx context.Context, key KeyRef, header map[string]interface{}, claims map[string]interface{}) ([]byte, error) {
+	signingKey, err := l.loadKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := jws.NewHeaders()
+	for name, value := range header {
+		if err := headers.Set(name, value); err != nil {
+			return nil, fmt.Errorf("error setting jwt header %s: %w", name, err)
+		}
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return jws.Sign(payload, jws.WithKey(joseAlgorithm(signingKey), signingKey, jws.WithProtectedHeaders(headers)))
+}
+
+func (l *Local) SignCwt(ctx context.Context, key KeyRef, header map[int]interface{}, claims []byte) ([]byte, error) {
+	signingKey, err := l.loadKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return signCoseSign1(signingKey, header, claims)
+}
+
+func (l *Local) AddProof(ctx context.Context, key KeyRef, credential map[string]interface{}) (map[string]interface{}, error) {
+	signingKey, err := l.loadKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return addDataIntegrityProof(signingKey, key.VerificationMethod, credential)
+}
+
+func (l *Local) loadKey(key KeyRef) (crypto.Signer, error) {
+	if !validName.MatchString(key.TenantId) || !validName.MatchString(key.Key) {
+		return nil, fmt.Errorf("%w: invalid key name %s/%s", ErrKeyNotFound, key.TenantId, key.Key)
+	}
+
+	id := key.TenantId + "/" + key.Key
+
+	l.mutex.RLock()
+	signingKey, ok := l.keys[id]
+	l.mutex.RUnlock()
+	if ok {
+		return signingKey, nil
+	}
+
+	signingKey, err := readKey(filepath.Join(l.keyDir, key.TenantId, key.Key))
+	if err != nil {
+		return nil, err
+	}
+
+	l.mutex.Lock()
+	l.keys[id] = signingKey
+	l.mutex.Unlock()
+
+	return signingKey, nil
+}
+
+// readKey reads the private key from path with the extension .pem or .jwk.
+func readKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path + ".pem")
+	isPem := err == nil
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = os.ReadFile(path + ".jwk")
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading key: %w", err)
+	}
+
+	parsed, err := jwk.ParseKey(data, jwk.WithPEM(isPem))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing key %s: %w", path, err)
+	}
+
+	var raw interface{}
+	if err := parsed.Raw(&raw); err != nil {
+		return nil, fmt.Errorf("error parsing key %s: %w", path, err)
+	}
+
+	switch k := raw.(type) {
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve == elliptic.P256() {
+			return k, nil
+		}
+	}
+
+	return nil, ErrUnsupportedKey
+}
+
+func joseAlgorithm(key crypto.Signer) jwa.SignatureAlgorithm {
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		return jwa.EdDSA
+	}
+	return jwa.ES256
+}
+
+// signRaw signs data with Ed25519 or with ECDSA over the SHA-256 digest. ECDSA signatures are encoded as r || s like in JOSE and COSE.
+func signRaw(key crypto.Signer, data []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, data), nil
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(data)
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			return nil, err
+		}
+
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature, nil
+	}
+
+	return nil, ErrUnsupportedKey
+}
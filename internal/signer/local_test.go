@@ -0,0 +1,143 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/require"
+)
+
+func writePemKey(t *testing.T, dir string, tenantId string, name string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, tenantId), 0o700))
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, tenantId, name+".pem"), data, 0o600))
+}
+
+func writeJwkKey(t *testing.T, dir string, tenantId string, name string, key interface{}) {
+	jwkKey, err := jwk.FromRaw(key)
+	require.NoError(t, err)
+	data, err := json.Marshal(jwkKey)
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, tenantId), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, tenantId, name+".jwk"), data, 0o600))
+}
+
+func TestLocalSignJwtWithPemKey(t *testing.T) {
+	dir := t.TempDir()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writePemKey(t, dir, "tenant", "key", private)
+
+	signer := NewLocal(dir)
+	token, err := signer.SignJwt(context.Background(), KeyRef{TenantId: "tenant", Key: "key"}, map[string]interface{}{"typ": "statuslist+jwt", "kid": "did:web:example.com#key"}, map[string]interface{}{"sub": "https://example.com/status/1"})
+	require.NoError(t, err)
+
+	payload, err := jws.Verify(token, jws.WithKey(jwa.EdDSA, public))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"sub":"https://example.com/status/1"}`, string(payload))
+
+	message, err := jws.Parse(token)
+	require.NoError(t, err)
+	require.Equal(t, "statuslist+jwt", message.Signatures()[0].ProtectedHeaders().Type())
+}
+
+func TestLocalSignCwtWithJwkKey(t *testing.T) {
+	dir := t.TempDir()
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	writeJwkKey(t, dir, "tenant", "key", private)
+
+	signer := NewLocal(dir)
+	claims, err := cbor.Marshal(map[int]interface{}{2: "https://example.com/status/1"})
+	require.NoError(t, err)
+
+	cwt, err := signer.SignCwt(context.Background(), KeyRef{TenantId: "tenant", Key: "key"}, map[int]interface{}{16: "application/statuslist+cwt"}, claims)
+	require.NoError(t, err)
+
+	var tag cbor.RawTag
+	require.NoError(t, cbor.Unmarshal(cwt, &tag))
+	require.Equal(t, uint64(coseSign1Tag), tag.Number)
+
+	var message []cbor.RawMessage
+	require.NoError(t, cbor.Unmarshal(tag.Content, &message))
+	var protected, payload, signature []byte
+	require.NoError(t, cbor.Unmarshal(message[0], &protected))
+	require.NoError(t, cbor.Unmarshal(message[2], &payload))
+	require.NoError(t, cbor.Unmarshal(message[3], &signature))
+	require.Equal(t, claims, payload)
+
+	var header map[int]interface{}
+	require.NoError(t, cbor.Unmarshal(protected, &header))
+	require.Equal(t, int64(coseAlgES256), header[coseHeaderAlg])
+
+	toBeSigned, err := cbor.Marshal([]interface{}{coseSign1Label, protected, []byte{}, payload})
+	require.NoError(t, err)
+	digest := sha256.Sum256(toBeSigned)
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	require.True(t, ecdsa.Verify(&private.PublicKey, digest[:], r, s))
+}
+
+func TestLocalAddProof(t *testing.T) {
+	dir := t.TempDir()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writePemKey(t, dir, "tenant", "key", private)
+
+	credential := map[string]interface{}{
+		"@context": []string{"https://www.w3.org/2018/credentials/v1"},
+		"type":     []string{"VerifiableCredential"},
+		"issuer":   "did:web:example.com",
+	}
+
+	signer := NewLocal(dir)
+	secured, err := signer.AddProof(context.Background(), KeyRef{TenantId: "tenant", Key: "key", VerificationMethod: "did:web:example.com#key"}, credential)
+	require.NoError(t, err)
+	require.NotContains(t, credential, "proof")
+	require.Contains(t, secured["@context"], contextDataIntegrityV2)
+
+	proof := secured["proof"].(map[string]interface{})
+	require.Equal(t, cryptosuiteEdDSA, proof["cryptosuite"])
+	require.Equal(t, "did:web:example.com#key", proof["verificationMethod"])
+
+	proofValue := proof["proofValue"].(string)
+	delete(proof, "proofValue")
+	delete(secured, "proof")
+	hashData, err := proofHashData(proof, secured)
+	require.NoError(t, err)
+	require.Equal(t, "z"+encodeBase58(ed25519.Sign(private, hashData)), proofValue)
+}
+
+func TestLocalKeyNotFound(t *testing.T) {
+	signer := NewLocal(t.TempDir())
+
+	_, err := signer.SignJwt(context.Background(), KeyRef{TenantId: "tenant", Key: "missing"}, nil, nil)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, err = signer.SignJwt(context.Background(), KeyRef{TenantId: "..", Key: "key"}, nil, nil)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestEncodeBase58(t *testing.T) {
+	require.Equal(t, "", encodeBase58(nil))
+	require.Equal(t, "11", encodeBase58([]byte{0, 0}))
+	require.Equal(t, "StV1DL6CwTryKyV", encodeBase58([]byte("hello world")))
+}
@@ -0,0 +1,183 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	cloudeventprovider "github.com/eclipse-xfsc/cloud-event-provider"
+	messaging "github.com/eclipse-xfsc/nats-message-library"
+	"github.com/eclipse-xfsc/nats-message-library/common"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/google/uuid"
+)
+
+// SignerServiceSignCwtType asks the signer to wrap CBOR claims into a COSE_Sign1 structure.
+const SignerServiceSignCwtType = "signer.signCwt"
+
+// CreateCwtRequest asks the signer to sign the CBOR encoded claims in Payload with the protected Header and to return the COSE_Sign1 structure.
+type CreateCwtRequest struct {
+	common.Request
+	Namespace string `json:"namespace"`
+	Group     string `json:"group"`
+	Key       string `json:"key"`
+	Payload   []byte `json:"payload"`
+	Header    []byte `json:"header"`
+}
+
+type CreateCwtReply struct {
+	common.Reply
+	Cwt []byte `json:"cwt"`
+}
+
+// Nats signs tokens over the signer topic. It does not add proofs. The connection is opened with the first request and shared afterwards.
+type Nats struct {
+	config  cloudeventprovider.NatsConfig
+	topic   string
+	options Options
+	mutex   sync.Mutex
+	client  *cloudeventprovider.CloudEventProviderClient
+}
+
+func NewNats(config cloudeventprovider.NatsConfig, topic string, options Options) *Nats {
+	return &Nats{
+		config:  config,
+		topic:   topic,
+		options: options,
+	}
+}
+
+func (n *Nats) SignJwt(ctx context.Context, key KeyRef, header map[string]interface{}, claims map[string]interface{}) ([]byte, error) {
+	pb, err := json.Marshal(claims)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pbh, err := json.Marshal(header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	payload := messaging.CreateTokenRequest{
+		Request: common.Request{
+			TenantId:  key.TenantId,
+			RequestId: uuid.NewString(),
+		},
+		Namespace: key.Namespace,
+		Group:     key.Group,
+		Key:       key.Key,
+		Payload:   pb,
+		Header:    pbh,
+	}
+
+	var tok messaging.CreateTokenReply
+	if err := n.request(ctx, messaging.SignerServiceSignTokenType, payload, &tok, &tok.Reply); err != nil {
+		return nil, err
+	}
+
+	return tok.Token, nil
+}
+
+func (n *Nats) SignCwt(ctx context.Context, key KeyRef, header map[int]interface{}, claims []byte) ([]byte, error) {
+	protected, err := cbor.Marshal(header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	request := CreateCwtRequest{
+		Request: common.Request{
+			TenantId:  key.TenantId,
+			RequestId: uuid.NewString(),
+		},
+		Namespace: key.Namespace,
+		Group:     key.Group,
+		Key:       key.Key,
+		Payload:   claims,
+		Header:    protected,
+	}
+
+	var reply CreateCwtReply
+	if err := n.request(ctx, SignerServiceSignCwtType, request, &reply, &reply.Reply); err != nil {
+		return nil, err
+	}
+
+	return reply.Cwt, nil
+}
+
+func (n *Nats) AddProof(ctx context.Context, key KeyRef, credential map[string]interface{}) (map[string]interface{}, error) {
+	return nil, ErrUnsupported
+}
+
+func (n *Nats) Close() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.client != nil {
+		n.client.Close()
+		n.client = nil
+	}
+}
+
+// request sends the payload as event of eventType and decodes the answer into result. Failures of the transport are mapped to ErrUnavailable, error replies of the signer to ErrRejected.
+func (n *Nats) request(ctx context.Context, eventType string, payload interface{}, result interface{}, reply *common.Reply) error {
+	b, err := json.Marshal(payload)
+
+	if err != nil {
+		return err
+	}
+
+	event, err := cloudeventprovider.NewEvent("statuslist-service", eventType, b)
+
+	if err != nil {
+		return err
+	}
+
+	return retry(ctx, n.options, func(ctx context.Context) error {
+		client, err := n.connect()
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+
+		rep, err := client.RequestCtx(ctx, event)
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+
+		if err = json.Unmarshal(rep.Data(), result); err != nil {
+			return err
+		}
+
+		if reply.Error != nil {
+			return fmt.Errorf("%w: signer service call error. result was: %s", ErrRejected, reply.Error.Msg)
+		}
+
+		return nil
+	})
+}
+
+func (n *Nats) connect() (*cloudeventprovider.CloudEventProviderClient, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.client != nil {
+		return n.client, nil
+	}
+
+	client, err := cloudeventprovider.New(cloudeventprovider.Config{
+		Protocol: cloudeventprovider.ProtocolTypeNats,
+		Settings: n.config,
+	}, cloudeventprovider.Req, n.topic)
+
+	if err != nil {
+		return nil, err
+	}
+
+	n.client = client
+	return client, nil
+}
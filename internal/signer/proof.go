@@ -0,0 +1,142 @@
+package signer
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+	"time"
+)
+
+const (
+	contextCredentialsV2   = "https://www.w3.org/ns/credentials/v2"
+	contextDataIntegrityV2 = "https://w3id.org/security/data-integrity/v2"
+	cryptosuiteEdDSA       = "eddsa-jcs-2022"
+	cryptosuiteECDSA       = "ecdsa-jcs-2019"
+	base58Alphabet         = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+)
+
+// addDataIntegrityProof secures the credential with the JCS cryptosuites of https://www.w3.org/TR/vc-di-eddsa/ and https://www.w3.org/TR/vc-di-ecdsa/.
+func addDataIntegrityProof(key crypto.Signer, verificationMethod string, credential map[string]interface{}) (map[string]interface{}, error) {
+	secured := make(map[string]interface{}, len(credential)+1)
+	for name, value := range credential {
+		secured[name] = value
+	}
+	delete(secured, "proof")
+	secured["@context"] = withDataIntegrityContext(secured["@context"])
+
+	cryptosuite := cryptosuiteECDSA
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		cryptosuite = cryptosuiteEdDSA
+	}
+
+	proof := map[string]interface{}{
+		"type":               "DataIntegrityProof",
+		"cryptosuite":        cryptosuite,
+		"created":            time.Now().UTC().Format(time.RFC3339),
+		"verificationMethod": verificationMethod,
+		"proofPurpose":       "assertionMethod",
+	}
+
+	hashData, err := proofHashData(proof, secured)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signRaw(key, hashData)
+	if err != nil {
+		return nil, err
+	}
+
+	proof["proofValue"] = "z" + encodeBase58(signature)
+	secured["proof"] = proof
+
+	return secured, nil
+}
+
+// proofHashData concatenates the hashes of the canonical proof configuration and the canonical document.
+func proofHashData(proof map[string]interface{}, document map[string]interface{}) ([]byte, error) {
+	proofConfig := make(map[string]interface{}, len(proof)+1)
+	for name, value := range proof {
+		proofConfig[name] = value
+	}
+	proofConfig["@context"] = document["@context"]
+
+	canonicalConfig, err := canonicalize(proofConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalDocument, err := canonicalize(document)
+	if err != nil {
+		return nil, err
+	}
+
+	configHash := sha256.Sum256(canonicalConfig)
+	documentHash := sha256.Sum256(canonicalDocument)
+
+	return append(configHash[:], documentHash[:]...), nil
+}
+
+// canonicalize serializes value with sorted keys and without HTML escaping. This matches the JSON Canonicalization Scheme (RFC 8785) for the string and integer values of the credentials built by the service.
+func canonicalize(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// withDataIntegrityContext adds the Data Integrity context to credentials of the data model 1.1, the context of the data model 2.0 already contains it.
+func withDataIntegrityContext(context interface{}) interface{} {
+	var contexts []interface{}
+	switch c := context.(type) {
+	case []string:
+		for _, value := range c {
+			contexts = append(contexts, value)
+		}
+	case []interface{}:
+		contexts = append(contexts, c...)
+	default:
+		return context
+	}
+
+	for _, value := range contexts {
+		if value == contextCredentialsV2 || value == contextDataIntegrityV2 {
+			return context
+		}
+	}
+
+	return append(contexts, contextDataIntegrityV2)
+}
+
+func encodeBase58(data []byte) string {
+	number := new(big.Int).SetBytes(data)
+	base := big.NewInt(58)
+	remainder := new(big.Int)
+
+	var encoded []byte
+	for number.Sign() > 0 {
+		number.DivMod(number, base, remainder)
+		encoded = append(encoded, base58Alphabet[remainder.Int64()])
+	}
+
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+
+	return string(encoded)
+}
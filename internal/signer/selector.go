@@ -0,0 +1,78 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+)
+
+// Remote combines the signer service interfaces of the earlier versions: tokens are signed over Nats, proofs are added over http.
+type Remote struct {
+	tokens Signer
+	proofs Signer
+}
+
+func NewRemote(tokens Signer, proofs Signer) *Remote {
+	return &Remote{
+		tokens: tokens,
+		proofs: proofs,
+	}
+}
+
+func (r *Remote) SignJwt(ctx context.Context, key KeyRef, header map[string]interface{}, claims map[string]interface{}) ([]byte, error) {
+	return r.tokens.SignJwt(ctx, key, header, claims)
+}
+
+func (r *Remote) SignCwt(ctx context.Context, key KeyRef, header map[int]interface{}, claims []byte) ([]byte, error) {
+	return r.tokens.SignCwt(ctx, key, header, claims)
+}
+
+func (r *Remote) AddProof(ctx context.Context, key KeyRef, credential map[string]interface{}) (map[string]interface{}, error) {
+	return r.proofs.AddProof(ctx, key, credential)
+}
+
+// Selector delegates to the backend configured for the tenant of the key.
+type Selector struct {
+	tenants  map[string]Signer
+	fallback Signer
+}
+
+// NewSelector maps the backend names of the tenants and of the default backend to the given backends.
+func NewSelector(backends map[string]Signer, defaultBackend string, tenantBackends map[string]string) (*Selector, error) {
+	fallback, ok := backends[defaultBackend]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBackend, defaultBackend)
+	}
+
+	tenants := make(map[string]Signer, len(tenantBackends))
+	for tenantId, backend := range tenantBackends {
+		signer, ok := backends[backend]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s for tenant %s", ErrUnknownBackend, backend, tenantId)
+		}
+		tenants[tenantId] = signer
+	}
+
+	return &Selector{
+		tenants:  tenants,
+		fallback: fallback,
+	}, nil
+}
+
+func (s *Selector) SignJwt(ctx context.Context, key KeyRef, header map[string]interface{}, claims map[string]interface{}) ([]byte, error) {
+	return s.backend(key.TenantId).SignJwt(ctx, key, header, claims)
+}
+
+func (s *Selector) SignCwt(ctx context.Context, key KeyRef, header map[int]interface{}, claims []byte) ([]byte, error) {
+	return s.backend(key.TenantId).SignCwt(ctx, key, header, claims)
+}
+
+func (s *Selector) AddProof(ctx context.Context, key KeyRef, credential map[string]interface{}) (map[string]interface{}, error) {
+	return s.backend(key.TenantId).AddProof(ctx, key, credential)
+}
+
+func (s *Selector) backend(tenantId string) Signer {
+	if signer, ok := s.tenants[tenantId]; ok {
+		return signer
+	}
+	return s.fallback
+}
@@ -0,0 +1,62 @@
+package signer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type namedSigner string
+
+func (n namedSigner) SignJwt(ctx context.Context, key KeyRef, header map[string]interface{}, claims map[string]interface{}) ([]byte, error) {
+	return []byte(n), nil
+}
+
+func (n namedSigner) SignCwt(ctx context.Context, key KeyRef, header map[int]interface{}, claims []byte) ([]byte, error) {
+	return []byte(n), nil
+}
+
+func (n namedSigner) AddProof(ctx context.Context, key KeyRef, credential map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"signer": string(n)}, nil
+}
+
+func TestSelectorSelectsBackendOfTenant(t *testing.T) {
+	backends := map[string]Signer{
+		BackendNats:  namedSigner(BackendNats),
+		BackendLocal: namedSigner(BackendLocal),
+	}
+
+	selector, err := NewSelector(backends, BackendNats, map[string]string{"tenant1": BackendLocal})
+	require.NoError(t, err)
+
+	token, err := selector.SignJwt(context.Background(), KeyRef{TenantId: "tenant1"}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, BackendLocal, string(token))
+
+	token, err = selector.SignCwt(context.Background(), KeyRef{TenantId: "tenant2"}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, BackendNats, string(token))
+}
+
+func TestSelectorUnknownBackend(t *testing.T) {
+	backends := map[string]Signer{BackendLocal: namedSigner(BackendLocal)}
+
+	_, err := NewSelector(backends, BackendHttp, nil)
+	require.ErrorIs(t, err, ErrUnknownBackend)
+
+	_, err = NewSelector(backends, BackendLocal, map[string]string{"tenant1": "vault"})
+	require.ErrorIs(t, err, ErrUnknownBackend)
+}
+
+func TestRemoteCombinesBackends(t *testing.T) {
+	remote := NewRemote(namedSigner(BackendNats), namedSigner(BackendHttp))
+
+	token, err := remote.SignJwt(context.Background(), KeyRef{}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, BackendNats, string(token))
+
+	credential, err := remote.AddProof(context.Background(), KeyRef{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, BackendHttp, credential["signer"])
+}
@@ -0,0 +1,85 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrKeyNotFound = errors.New("signing key not found")
+var ErrUnsupportedKey = errors.New("signing key must be an Ed25519 or P-256 key")
+var ErrUnsupported = errors.New("signer backend does not support this format")
+var ErrUnavailable = errors.New("signer backend is not available")
+var ErrRejected = errors.New("signer backend rejected the request")
+var ErrUnknownBackend = errors.New("unknown signer backend")
+var ErrInvalidProof = errors.New("proof could not be verified")
+
+// Backends which can be selected for a tenant.
+const (
+	BackendRemote = "remote"
+	BackendNats   = "nats"
+	BackendHttp   = "http"
+	BackendLocal  = "local"
+)
+
+// KeyRef identifies the key which signs a status list. Remote signers resolve it by namespace, group and key name, the local signer by tenant and key name.
+type KeyRef struct {
+	TenantId  string
+	Namespace string
+	Group     string
+	Key       string
+	// VerificationMethod is the id of the public key which verifiers resolve, e.g. did:web:example.com#key
+	VerificationMethod string
+}
+
+// Signer secures status lists in the formats the service offers.
+type Signer interface {
+	// SignJwt returns a compact JWS of the claims. The signer adds the alg to the header.
+	SignJwt(ctx context.Context, key KeyRef, header map[string]interface{}, claims map[string]interface{}) ([]byte, error)
+	// SignCwt returns a COSE_Sign1 structure of the CBOR encoded claims. The signer adds the alg to the protected header.
+	SignCwt(ctx context.Context, key KeyRef, header map[int]interface{}, claims []byte) ([]byte, error)
+	// AddProof returns the credential with a Data Integrity proof.
+	AddProof(ctx context.Context, key KeyRef, credential map[string]interface{}) (map[string]interface{}, error)
+}
+
+// Verifier checks the proofs of credentials issued elsewhere, independent of the backend which signs the lists of a tenant.
+type Verifier interface {
+	// VerifyProof checks the proof of the credential. The key only selects namespace and group of the signer service. It fails with ErrInvalidProof if the proof does not hold.
+	VerifyProof(ctx context.Context, key KeyRef, credential []byte) error
+}
+
+// Options are shared by the backends which call a signer service.
+type Options struct {
+	// Timeout limits every single attempt, zero disables it.
+	Timeout time.Duration
+	// Retries is the number of additional attempts after the signer service was not available.
+	Retries    int
+	RetryDelay time.Duration
+}
+
+// retry runs call until it succeeds, fails with another error than ErrUnavailable or the retries are used up.
+func retry(ctx context.Context, options Options, call func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; attempt <= options.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(options.RetryDelay):
+			}
+		}
+
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if options.Timeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, options.Timeout)
+		}
+		err = call(callCtx)
+		cancel()
+
+		if !errors.Is(err, ErrUnavailable) {
+			return err
+		}
+	}
+
+	return err
+}
@@ -2,12 +2,17 @@ package main
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"syscall"
 
 	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
 	logPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
 	"github.com/eclipse-xfsc/statuslist-service/internal/api"
 	"github.com/eclipse-xfsc/statuslist-service/internal/config"
 	"github.com/eclipse-xfsc/statuslist-service/internal/database"
+	"github.com/eclipse-xfsc/statuslist-service/internal/entity"
+	"github.com/eclipse-xfsc/statuslist-service/internal/signer"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -20,6 +25,16 @@ func main() {
 
 	currentConf := &config.CurrentStatusListConfig
 
+	if err := currentConf.Validate(); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+
+	defaultOptions := entity.ListOptions{
+		Bits:           currentConf.ListBits,
+		AllocationMode: currentConf.AllocationMode,
+		Purpose:        currentConf.ListPurpose,
+	}
+
 	logger, err := logPkg.New(currentConf.LogLevel, currentConf.IsDev, nil)
 	if err != nil {
 		log.Fatalf("failed to init logger: %v", err)
@@ -29,11 +44,46 @@ func main() {
 	config.SetLogger(*logger)
 	dbConf := &currentConf.Database
 
-	db, err := database.New(ctx, *dbConf, currentConf.ListSizeInBytes)
+	db, err := database.New(ctx, *dbConf, currentConf.ListSizeInBytes, defaultOptions)
 
 	if err != nil {
 		log.Fatalf("database cant be established: %v", err)
 	}
 
-	api.Listen(db, currentConf)
+	sign, verifier, err := newSigner(currentConf)
+
+	if err != nil {
+		log.Fatalf("invalid signer configuration: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	api.Listen(ctx, db, sign, verifier, currentConf)
+}
+
+// newSigner creates every signer backend and selects them by the configured signer type of the tenants. The proofs of fetched status lists are verified by the signer service for every tenant.
+func newSigner(conf *config.StatusListConfiguration) (signer.Signer, signer.Verifier, error) {
+	options := signer.Options{
+		Timeout:    conf.SignerTimeout,
+		Retries:    conf.SignerRetries,
+		RetryDelay: conf.SignerRetryDelay,
+	}
+
+	natsSigner := signer.NewNats(conf.Nats, conf.SignerTopic, options)
+	httpSigner := signer.NewHttp(conf.SignerUrl, options)
+
+	backends := map[string]signer.Signer{
+		signer.BackendRemote: signer.NewRemote(natsSigner, httpSigner),
+		signer.BackendNats:   natsSigner,
+		signer.BackendHttp:   httpSigner,
+		signer.BackendLocal:  signer.NewLocal(conf.SignerKeyDir),
+	}
+
+	selector, err := signer.NewSelector(backends, conf.SignerType, conf.TenantSigner)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return selector, httpSigner, nil
 }